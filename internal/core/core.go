@@ -4,16 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/pattern"
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/queue"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/urlnorm"
 	"log"
 	"net/url"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 //go:generate mockgen -source core.go -destination mock_core.go -package core
 type (
 	PageLoader interface {
-		GetPageLinks(context.Context, string) ([]string, error)
+		LoadPage(context.Context, string) (*Page, error)
+		LoadRobots(context.Context, string) (*robots.Robots, error)
+		LoadSitemap(context.Context, string) (*Sitemap, error)
 	}
 
 	Reporter interface {
@@ -27,11 +36,14 @@ type (
 		pageLoader PageLoader
 		reporter   Reporter
 
-		// levelMap stores processed URLs and a parent.
+		// levelMap stores processed URLs and a parent. Pages are stored by their canonical URLs.
 		// In case we encounter a URL again, we can compare its level and leave the one with a lower level,
 		// so resulting map will have more entries.
 		levelMap map[string]PageLevelItem
 
+		// nonCanonical stores crawled URLs that have other canonical URL
+		nonCanonical map[string]string
+
 		// Root element in a references tree
 		root *PageItem
 
@@ -39,20 +51,198 @@ type (
 		tasksQueue *queue.ConcurrentQueue
 
 		rootDomain string
+
+		// startURL is a normalized URL of the start page
+		startURL string
+
+		// normalizer is nil if URLs normalization is disabled
+		normalizer *urlnorm.Normalizer
+
+		// include and exclude are compiled Config.Include and Config.Exclude
+		include []*pattern.Pattern
+		exclude []*pattern.Pattern
+
+		// robots stores robots.txt rules per scheme and host
+		robots   map[string]*hostRobots
+		robotsMu sync.Mutex
+
+		// skipped stores URLs that were not crawled and a reason why
+		skipped map[string]string
+
+		// failed stores URLs that failed to load and an error
+		failed map[string]string
+
+		// redirects stores crawled URLs that redirected and their final URLs
+		redirects map[string]string
+
+		// clusters are hreflang clusters of crawled pages
+		clusters []*AlternateCluster
+
+		// seeds are URLs from existing sitemaps that are pushed as tasks along with the start page links
+		seeds []string
 	}
 
 	Config struct {
+		// URL is the start page URL. Its host is the root domain of the crawl scope.
 		URL      string
 		NWorkers int
 		MaxDepth int
+
+		// Normalize enables URLs normalization before deduplication.
+		Normalize     bool
+		Normalization urlnorm.Config
+
+		// Scope defines hosts that belong to the crawled site. AllowedHosts are used by ScopeAllowlist mode.
+		Scope        ScopeMode
+		AllowedHosts []string
+
+		// Include and Exclude are patterns of URL path and query that limit crawled and saved pages.
+		// A pattern is a glob or a regular expression with "re:" prefix.
+		// If Include isn't empty a page should match any of its patterns, and it shouldn't match any of Exclude patterns.
+		// The start page is crawled anyway, but it's excluded from the sitemap if it doesn't match.
+		Include []string
+		Exclude []string
+
+		// HonorNofollowLinks disables crawling of links with rel="nofollow".
+		HonorNofollowLinks bool
+
+		// RespectRobots enables robots.txt rules. Rules of the group that matches RobotsAgent product token are applied.
+		RespectRobots bool
+		RobotsAgent   string
+
+		// SeedSitemaps enables crawl seeding from sitemaps listed in robots.txt and in Sitemaps.
+		// Sitemap indexes are read recursively. Seeded pages get SeedLevel depth (0 or 1) and the start page as a parent.
+		SeedSitemaps bool
+		Sitemaps     []string
+		SeedLevel    int
+
+		// Limiter is a per host rate limiter that PageLoader waits for before every request.
+		// With RespectRobots robots.txt Crawl-delay of every host is set to it. It may be nil.
+		Limiter *ratelimit.Limiter
+	}
+
+	// hostRobots are robots.txt rules of a host that are loaded once
+	hostRobots struct {
+		once     sync.Once
+		group    *robots.Group
+		sitemaps []string
+		err      error
 	}
 )
 
 type (
 
+	// Page is a result of page loading
+	Page struct {
+		Links []Link
+		Meta  PageMeta
+
+		// NoIndex and NoFollow are robots directives of the page from <meta name="robots"> and X-Robots-Tag.
+		NoIndex  bool
+		NoFollow bool
+
+		// Canonical is an absolute URL from <link rel="canonical"> or Link header. It's empty if a page has none.
+		Canonical string
+
+		// StatusCode is an HTTP status of the page response. Pages with non-2xx status are not saved.
+		// Zero value means it's unknown, and the page is treated as successful.
+		StatusCode int
+
+		// ContentType is a media type of the page without parameters, e.g. text/html.
+		ContentType string
+
+		// FinalURL is a URL of the page after redirects. It's empty if the page wasn't redirected.
+		// Redirects are URLs that responded with a redirect starting with the requested one.
+		FinalURL  string
+		Redirects []string
+	}
+
+	// Link is a link found on a page
+	Link struct {
+		URL string
+
+		// NoFollow is true if a link has rel="nofollow"
+		NoFollow bool
+
+		// Tag and Attr are a tag and an attribute the link was extracted from, e.g. "a" and "href"
+		Tag  string
+		Attr string
+	}
+
+	// Sitemap is a result of sitemap loading.
+	// A sitemap has URLs and a sitemap index has Sitemaps.
+	Sitemap struct {
+		URLs     []string
+		Sitemaps []string
+	}
+
+	// PageMeta is a page metadata that is passed to a reporter
+	PageMeta struct {
+		// LastModified is taken from Last-Modified header or from page content. Zero value means it's unknown.
+		LastModified time.Time
+
+		// Images are absolute URLs of page images from <img>, <picture> and og:image.
+		Images []string
+
+		// Videos are videos of the page from <video>, player iframes and JSON-LD.
+		Videos []Video
+
+		// Article is news article metadata. It's nil if the page has no publication date.
+		Article *Article
+
+		// Alternates are localized versions of the page from <link rel="alternate" hreflang>.
+		Alternates []Alternate
+	}
+
+	// Alternate is a localized version of a page.
+	Alternate struct {
+		// Lang is a lowercased hreflang value, e.g. "en", "de-at" or "x-default"
+		Lang string
+		URL  string
+	}
+
+	// Article is metadata of a news article.
+	Article struct {
+		PublishedTime time.Time
+		Title         string
+
+		// Language is a language code of the article, e.g. "en" or "en-US". It's empty if it's unknown.
+		Language string
+
+		// PublicationName is a name of the news publication, e.g. a site name.
+		PublicationName string
+	}
+
+	// Video is a video found on a page. URLs are absolute.
+	// ContentURL is a URL of a media file, PlayerURL is a URL of an embedded player. A video has any of them.
+	Video struct {
+		ThumbnailURL string
+		Title        string
+		Description  string
+		ContentURL   string
+		PlayerURL    string
+
+		// Duration is zero if it's unknown
+		Duration time.Duration
+	}
+
 	// PageItem is an entry for resulting references tree
 	PageItem struct {
-		URL      string
+		URL string
+
+		// Level is a depth of the page in references tree. Root page has 0 level.
+		Level int
+		Meta  PageMeta
+
+		// ExcludeReason is set if the page was crawled but should not get to the sitemap.
+		ExcludeReason string
+
+		// StatusCode is an HTTP status of the page. It's zero if it's unknown, e.g. the page failed to load.
+		StatusCode int
+
+		// Cluster is a group of localized versions the page belongs to. It's nil if the page has no alternates.
+		Cluster *AlternateCluster
+
 		Children []*PageItem
 	}
 
@@ -74,7 +264,25 @@ type (
 		url    string
 		level  int
 		links  []string
+		meta   PageMeta
 		parent *PageItem
+
+		// skipReason is set when a page wasn't crawled
+		skipReason string
+
+		// excludeReason is set when a page was crawled but should not get to the sitemap
+		excludeReason string
+
+		// failReason is set when a page failed to load
+		failReason string
+
+		// redirectedFrom is a requested URL if the page was redirected to url
+		redirectedFrom string
+
+		// statusCode is an HTTP status of the page, it's zero if it's unknown
+		statusCode int
+
+		canonical string
 	}
 )
 
@@ -105,22 +313,87 @@ func (item *PageItem) dropChild(url string) {
 
 // New returns and instance of Core
 func New(config Config, pageLoader PageLoader, reporter Reporter) *Core {
+
+	var normalizer *urlnorm.Normalizer
+	if config.Normalize {
+		normalizer = urlnorm.New(config.Normalization)
+	}
+
 	return &Core{
 		config:     config,
+		normalizer: normalizer,
 		pageLoader: pageLoader,
 		reporter:   reporter,
 		levelMap:   make(map[string]PageLevelItem),
 		tasksQueue: queue.New(),
+		robots:     make(map[string]*hostRobots),
+		skipped:    make(map[string]string),
+		failed:     make(map[string]string),
+		redirects:  make(map[string]string),
+
+		nonCanonical: make(map[string]string),
 	}
 }
 
+// Variants returns non-canonical URLs that were crawled grouped by their canonical URLs.
+// It should be called after Run() finishes.
+func (cr *Core) Variants() map[string][]string {
+
+	res := make(map[string][]string)
+
+	for variant, canonical := range cr.nonCanonical {
+		res[canonical] = append(res[canonical], variant)
+	}
+
+	for _, variants := range res {
+		sort.Strings(variants)
+	}
+
+	return res
+}
+
+// Skipped returns URLs that were not crawled and a reason for every URL.
+// It should be called after Run() finishes.
+func (cr *Core) Skipped() map[string]string {
+	return cr.skipped
+}
+
+// Failed returns URLs that failed to load (after loader retries) and an error for every URL.
+// Failed pages are not saved to the sitemap. It should be called after Run() finishes.
+func (cr *Core) Failed() map[string]string {
+	return cr.failed
+}
+
+// Redirects returns crawled URLs that redirected and their final URLs.
+// Redirecting URLs are not saved, final URLs are saved instead. It should be called after Run() finishes.
+func (cr *Core) Redirects() map[string]string {
+	return cr.redirects
+}
+
 // Run starts links collection.
 // It parses pages and collects links and recursively requests links for these pages.
 // It finishes when all links are collected or MaxDepth is reached.
 func (cr *Core) Run(ctx context.Context) error {
 
+	var err error
+
+	if cr.include, err = pattern.CompileList(cr.config.Include); err != nil {
+		return fmt.Errorf("bad include pattern: %w", err)
+	}
+
+	if cr.exclude, err = pattern.CompileList(cr.config.Exclude); err != nil {
+		return fmt.Errorf("bad exclude pattern: %w", err)
+	}
+
+	startURL, err := cr.normalize(cr.config.URL)
+	if err != nil {
+		return fmt.Errorf("bad URL [%v]: %w", cr.config.URL, err)
+	}
+
+	cr.startURL = startURL
+
 	// root domain URL
-	rootDomain, err := url.Parse(cr.config.URL)
+	rootDomain, err := url.Parse(cr.startURL)
 	if err != nil {
 		return fmt.Errorf("bad URL [%v]: %w", cr.config.URL, err)
 	}
@@ -130,7 +403,11 @@ func (cr *Core) Run(ctx context.Context) error {
 		return fmt.Errorf("bad root URL [%v]: %w", cr.config.URL, err)
 	}
 
-	cr.rootDomain = domainURL.Hostname()
+	cr.rootDomain = strings.ToLower(domainURL.Hostname())
+
+	if cr.config.SeedSitemaps {
+		cr.seeds = cr.collectSeeds(ctx, domainURL)
+	}
 
 	chanResults := make(chan TaskResult)
 	chanErr := make(chan error)
@@ -144,7 +421,7 @@ func (cr *Core) Run(ctx context.Context) error {
 
 	wgWorkers := sync.WaitGroup{}
 	for i := 0; i < cr.config.NWorkers; i++ {
-		cr.runWorker(ctx, &wgWorkers, chanResults, nil)
+		cr.runWorker(ctx, &wgWorkers, chanResults, chanErr)
 	}
 
 	cr.runTasksManager(ctx, chanResults)
@@ -155,6 +432,21 @@ func (cr *Core) Run(ctx context.Context) error {
 	close(chanResults)
 	close(chanErr)
 
+	if cr.root == nil {
+		if reason, ok := cr.skipped[cr.startURL]; ok {
+			return fmt.Errorf("start page was skipped: %v", reason)
+		}
+
+		return errors.New("start page was not crawled")
+	}
+
+	// there's nothing to save if the start page failed, and it wasn't seeded from sitemaps
+	if reason, ok := cr.failed[cr.startURL]; ok && len(cr.root.Children) == 0 {
+		return fmt.Errorf("start page failed to load: %v", reason)
+	}
+
+	cr.clusters = buildClusters(cr.root)
+
 	if err := cr.reporter.Save(cr.root); err != nil {
 		return fmt.Errorf("failed to save results: %w", err)
 	}
@@ -169,7 +461,7 @@ func (cr *Core) runTasksManager(
 ) {
 	cr.tasksQueue.Push(Task{
 		level:  0,
-		url:    cr.config.URL,
+		url:    cr.startURL,
 		parent: nil,
 	})
 	tasksCounter := 1
@@ -184,62 +476,124 @@ func (cr *Core) runTasksManager(
 				return
 			}
 
-			pgLvlItem := PageLevelItem{
-				level:  res.level,
-				parent: res.parent,
+			tasksCounter += cr.handleResult(res)
+			tasksCounter--
+
+			if tasksCounter == 0 {
+				return
 			}
+		}
+	}
+}
 
-			existingResult, ok := cr.levelMap[res.url]
+// handleResult puts a page into the references tree and pushes tasks for page links.
+// It returns a number of pushed tasks.
+func (cr *Core) handleResult(res TaskResult) int {
 
-			insertNewItem := true
-			if !ok {
-				cr.levelMap[res.url] = pgLvlItem
-			} else {
-				// we already were on this page
-
-				if existingResult.level > res.level {
-					// existing page has greater depth, and we want to replace it
-					existingResult.parent.dropChild(res.url)
-					cr.levelMap[res.url] = pgLvlItem
-				} else {
-					// existing page has lower depth, and we have nothing to do with it
-					insertNewItem = false
-				}
-			}
+	if len(res.skipReason) > 0 {
+		log.Printf("skipping page [%v]: %v", res.url, res.skipReason)
+		cr.skipped[res.url] = res.skipReason
+		return 0
+	}
 
-			if insertNewItem {
+	if len(res.failReason) > 0 {
+		log.Printf("ERR: failed to load page [%v]: %v", res.url, res.failReason)
+		cr.failed[res.url] = res.failReason
+		res.excludeReason = "failed to load"
+	}
 
-				item := PageItem{
-					URL:      res.url,
-					Children: nil,
-				}
+	if len(res.redirectedFrom) > 0 {
+		cr.redirects[res.redirectedFrom] = res.url
+	}
 
-				if res.parent == nil {
-					cr.root = &item
-				} else {
-					res.parent.addChild(&item)
-				}
+	nTasks := 0
 
-				if res.level < cr.config.MaxDepth {
-					for _, r := range res.links {
-						cr.tasksQueue.Push(Task{
-							level:  res.level + 1,
-							url:    r,
-							parent: &item,
-						})
+	pageURL := cr.canonicalURL(res)
 
-						tasksCounter++
-					}
-				}
-			}
+	if len(res.excludeReason) == 0 {
+		if u, err := url.Parse(pageURL); err == nil {
+			_, res.excludeReason = cr.matchPatterns(u)
+		}
+	}
 
-			tasksCounter--
+	pgLvlItem := PageLevelItem{
+		level:  res.level,
+		parent: res.parent,
+	}
 
-			if tasksCounter == 0 {
-				return
+	existingResult, ok := cr.levelMap[pageURL]
+
+	insertNewItem := true
+	if !ok {
+		cr.levelMap[pageURL] = pgLvlItem
+	} else {
+		// we already were on this page
+
+		if existingResult.level > res.level {
+			// existing page has greater depth, and we want to replace it
+			existingResult.parent.dropChild(pageURL)
+			cr.levelMap[pageURL] = pgLvlItem
+		} else {
+			// existing page has lower depth, and we have nothing to do with it
+			insertNewItem = false
+		}
+	}
+
+	if insertNewItem {
+
+		item := PageItem{
+			URL:           pageURL,
+			Level:         res.level,
+			Meta:          res.meta,
+			ExcludeReason: res.excludeReason,
+			StatusCode:    res.statusCode,
+			Children:      nil,
+		}
+
+		if res.parent == nil {
+			cr.root = &item
+			nTasks += cr.pushSeeds(&item)
+		} else {
+			res.parent.addChild(&item)
+		}
+
+		if res.level < cr.config.MaxDepth {
+			for _, r := range res.links {
+				cr.tasksQueue.Push(Task{
+					level:  res.level + 1,
+					url:    r,
+					parent: &item,
+				})
+
+				nTasks++
 			}
 		}
 	}
+
+	return nTasks
+}
+
+// canonicalURL returns a URL the page should be stored with.
+// If a page has a canonical URL of the same domain it's used, and the page URL is tracked as a variant.
+// Canonical URLs of other domains are ignored.
+func (cr *Core) canonicalURL(res TaskResult) string {
+
+	if len(res.canonical) == 0 || res.canonical == res.url {
+		return res.url
+	}
+
+	u, err := url.ParseRequestURI(res.canonical)
+	if err != nil || !cr.inScope(u) {
+		log.Printf("ERR: page [%v] has canonical URL out of scope [%v]", res.url, res.canonical)
+		return res.url
+	}
+
+	if _, ok := cr.nonCanonical[res.url]; !ok {
+		log.Printf("page [%v] has canonical URL [%v]", res.url, res.canonical)
+		cr.nonCanonical[res.url] = res.canonical
+	}
+
+	return res.canonical
 }
 
 // runWorker runs a routine that pops tasks from a queue, requests a page,
@@ -271,39 +625,87 @@ func (cr *Core) runWorker(
 				continue
 			}
 
+			if cr.config.RespectRobots {
+				if allowed, reason := cr.checkRobots(ctx, task.url); !allowed {
+					chanResults <- TaskResult{
+						url:        task.url,
+						level:      task.level,
+						parent:     task.parent,
+						skipReason: reason,
+					}
+
+					continue
+				}
+			}
+
 			log.Printf("requesting page [%v] [%v]", task.url, task.level)
 
-			links, err := cr.pageLoader.GetPageLinks(ctx, task.url)
+			page, err := cr.pageLoader.LoadPage(ctx, task.url)
+
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+
+			res := TaskResult{
+				url:    task.url,
+				level:  task.level,
+				parent: task.parent,
+			}
+
+			if errors.Is(err, ErrOutOfScope) {
+				res.skipReason = err.Error()
+				chanResults <- res
+
+				continue
+			}
+
 			if err != nil {
-				if !errors.Is(err, context.Canceled) {
-					log.Printf("ERR: GetPageLinks: %v", err)
-				} else {
-					return
-				}
+				res.failReason = err.Error()
+				chanResults <- res
+
+				continue
+			}
+
+			if page == nil {
+				page = &Page{}
 			}
 
-			var domainURLs []string
+			if len(page.FinalURL) > 0 {
+				if reason := cr.followRedirect(ctx, &res, page.FinalURL); len(reason) > 0 {
+					res.skipReason = reason
+					chanResults <- res
 
-			for _, link := range links {
-				u, err := url.ParseRequestURI(link)
-				if u == nil {
-					chanError <- fmt.Errorf("loader returned a bad URL [%v]: %w", link, err)
 					continue
 				}
+			}
 
-				// ignoring links to other domains
-				if u.Hostname() != cr.rootDomain {
-					continue
+			res.statusCode = page.StatusCode
+
+			if page.StatusCode != 0 && (page.StatusCode < 200 || page.StatusCode >= 300) {
+				res.excludeReason = fmt.Sprintf("status %v", page.StatusCode)
+				chanResults <- res
+
+				continue
+			}
+
+			res.meta = page.Meta
+
+			if len(page.Meta.Alternates) > 0 {
+				res.meta.Alternates = cr.normalizeAlternates(page.Meta.Alternates, chanError)
+			}
+
+			if len(page.Canonical) > 0 {
+				if res.canonical, err = cr.normalize(page.Canonical); err != nil {
+					chanError <- fmt.Errorf("loader returned a bad canonical URL [%v]: %w", page.Canonical, err)
 				}
+			}
 
-				domainURLs = append(domainURLs, link)
+			if page.NoIndex {
+				res.excludeReason = "noindex"
 			}
 
-			res := TaskResult{
-				url:    task.url,
-				level:  task.level,
-				links:  domainURLs,
-				parent: task.parent,
+			if !page.NoFollow {
+				res.links = cr.filterLinks(page.Links, chanError)
 			}
 
 			chanResults <- res
@@ -312,6 +714,161 @@ func (cr *Core) runWorker(
 
 }
 
+// followRedirect replaces a task result URL with a normalized final URL of the redirected page.
+// If the final URL is out of scope or disallowed by robots.txt followRedirect returns a reason to skip the page.
+// The start page is checked as well, so a start URL redirecting to another host should be resolved by a caller.
+func (cr *Core) followRedirect(ctx context.Context, res *TaskResult, finalURL string) string {
+
+	finalURL, err := cr.normalize(finalURL)
+	if err != nil {
+		return fmt.Sprintf("redirects to a bad URL: %v", err)
+	}
+
+	if finalURL == res.url {
+		return ""
+	}
+
+	u, err := url.Parse(finalURL)
+	if err != nil {
+		return fmt.Sprintf("redirects to a bad URL: %v", err)
+	}
+
+	if !cr.inScope(u) {
+		return fmt.Sprintf("redirects out of scope to [%v]", finalURL)
+	}
+
+	if cr.config.RespectRobots {
+		if allowed, reason := cr.checkRobots(ctx, finalURL); !allowed {
+			return fmt.Sprintf("redirects to [%v] that is disallowed by %v", finalURL, reason)
+		}
+	}
+
+	res.redirectedFrom = res.url
+	res.url = finalURL
+
+	return ""
+}
+
+// filterLinks returns normalized links that should be crawled.
+// It drops links out of scope and, if it's configured, links with rel="nofollow".
+func (cr *Core) filterLinks(links []Link, chanError chan error) []string {
+
+	var domainURLs []string
+
+	for _, link := range links {
+
+		if link.NoFollow && cr.config.HonorNofollowLinks {
+			continue
+		}
+
+		linkURL, err := cr.normalize(link.URL)
+		if err != nil {
+			chanError <- fmt.Errorf("loader returned a bad URL [%v]: %w", link.URL, err)
+			continue
+		}
+
+		u, err := url.ParseRequestURI(linkURL)
+		if u == nil {
+			chanError <- fmt.Errorf("loader returned a bad URL [%v]: %w", link.URL, err)
+			continue
+		}
+
+		// ignoring links out of scope
+		if !cr.inScope(u) {
+			continue
+		}
+
+		if ok, _ := cr.matchPatterns(u); !ok {
+			continue
+		}
+
+		domainURLs = append(domainURLs, linkURL)
+	}
+
+	return domainURLs
+}
+
+// normalize returns a normalized URL if normalization is enabled or the URL as is.
+func (cr *Core) normalize(pageURL string) (string, error) {
+	if cr.normalizer == nil {
+		return pageURL, nil
+	}
+
+	return cr.normalizer.Normalize(pageURL)
+}
+
+// matchPatterns checks if a URL matches Include and Exclude patterns. If it doesn't matchPatterns returns a reason.
+func (cr *Core) matchPatterns(u *url.URL) (bool, string) {
+
+	target := pattern.PathAndQuery(u)
+
+	if len(cr.include) > 0 && !pattern.MatchAny(cr.include, target) {
+		return false, "doesn't match include patterns"
+	}
+
+	for _, p := range cr.exclude {
+		if p.Match(target) {
+			return false, fmt.Sprintf("matches exclude pattern [%v]", p)
+		}
+	}
+
+	return true, ""
+}
+
+// checkRobots checks if robots.txt allows to crawl a page. If it doesn't checkRobots returns a reason.
+// robots.txt of every host is loaded once. If it can't be loaded all host pages are disallowed.
+func (cr *Core) checkRobots(ctx context.Context, pageURL string) (bool, string) {
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return false, fmt.Sprintf("bad URL: %v", err)
+	}
+
+	hr := cr.getHostRobots(ctx, u)
+	if hr.err != nil {
+		return false, fmt.Sprintf("robots.txt is unavailable: %v", hr.err)
+	}
+
+	allowed, rule := hr.group.Allowed(pattern.PathAndQuery(u))
+	if !allowed {
+		return false, fmt.Sprintf("robots.txt [%v]", rule)
+	}
+
+	return true, ""
+}
+
+// getHostRobots returns robots.txt rules of the URL host loading them on the first call.
+// Crawl-delay of the host is set to Config.Limiter.
+func (cr *Core) getHostRobots(ctx context.Context, u *url.URL) *hostRobots {
+
+	host := u.Scheme + "://" + u.Host
+
+	cr.robotsMu.Lock()
+	hr, ok := cr.robots[host]
+	if !ok {
+		hr = &hostRobots{}
+		cr.robots[host] = hr
+	}
+	cr.robotsMu.Unlock()
+
+	hr.once.Do(func() {
+		rb, err := cr.pageLoader.LoadRobots(ctx, host+robots.Path)
+		if err != nil {
+			hr.err = err
+			return
+		}
+
+		hr.group = rb.Group(cr.config.RobotsAgent)
+		hr.sitemaps = rb.Sitemaps
+
+		if cr.config.Limiter != nil && hr.group != nil && hr.group.CrawlDelay > 0 {
+			cr.config.Limiter.SetMinInterval(u.Host, hr.group.CrawlDelay)
+		}
+	})
+
+	return hr
+}
+
 // func (cr *Core) getLinksList() []string {
 //
 // 	res := make([]string, 0, len(cr.levelMap))
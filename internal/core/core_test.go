@@ -2,11 +2,18 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/urlnorm"
 )
 
 func TestCore_Run(t *testing.T) {
@@ -207,9 +214,9 @@ func TestCore_Run(t *testing.T) {
 			mockCtrl := gomock.NewController(t)
 
 			mockPageLoader := NewMockPageLoader(mockCtrl)
-			mockPageLoader.EXPECT().GetPageLinks(gomock.Any(), gomock.Any()).AnyTimes().
-				DoAndReturn(func(ctx context.Context, url string) ([]string, error) {
-					return test.srcLinks[url], nil
+			mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+					return &Page{Links: toLinks(test.srcLinks[url])}, nil
 				})
 
 			res := make(map[string]interface{})
@@ -271,3 +278,700 @@ func TestCore_Run(t *testing.T) {
 
 	}
 }
+
+func TestCore_RunRobots(t *testing.T) {
+	t.Parallel()
+
+	srcLinks := map[string][]string{
+		"http://start.e.com": {
+			"http://start.e.com/link_00_01",
+			"http://start.e.com/admin/link_00_02",
+			"http://start.e.com/search?q=1",
+		},
+		"http://start.e.com/link_00_01": {
+			"http://start.e.com/link_01_01",
+		},
+	}
+
+	robotsTxt := "User-agent: *\nDisallow: /admin\nDisallow: /search?\n"
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			return &Page{Links: toLinks(srcLinks[url])}, nil
+		})
+	mockPageLoader.EXPECT().LoadRobots(gomock.Any(), "http://start.e.com/robots.txt").Times(1).
+		Return(robots.Parse(strings.NewReader(robotsTxt)))
+
+	var res map[string]interface{}
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		res = collectPaths(root)
+		return nil
+	})
+
+	cr := New(Config{
+		URL:           "http://start.e.com",
+		NWorkers:      5,
+		MaxDepth:      3,
+		RespectRobots: true,
+		RobotsAgent:   "sitemap-generator",
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]interface{}{
+		"[http://start.e.com]":                                 nil,
+		"[http://start.e.com]:[http://start.e.com/link_00_01]": nil,
+		"[http://start.e.com]:[http://start.e.com/link_00_01]:[http://start.e.com/link_01_01]": nil,
+	}, res)
+
+	require.Equal(t, map[string]string{
+		"http://start.e.com/admin/link_00_02": "robots.txt [Disallow: /admin]",
+		"http://start.e.com/search?q=1":       "robots.txt [Disallow: /search?]",
+	}, cr.Skipped())
+}
+
+func TestCore_RunRobotsUnavailable(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadRobots(gomock.Any(), gomock.Any()).Return(nil, errors.New("status 503"))
+
+	mockReporter := NewMockReporter(mockCtrl)
+
+	cr := New(Config{
+		URL:           "http://start.e.com",
+		NWorkers:      5,
+		MaxDepth:      3,
+		RespectRobots: true,
+	}, mockPageLoader, mockReporter)
+
+	require.Error(t, cr.Run(context.Background()))
+	require.Contains(t, cr.Skipped(), "http://start.e.com")
+}
+
+// collectPaths returns paths of all tree items in [parent]:[child] form.
+func collectPaths(root *PageItem) map[string]interface{} {
+
+	res := make(map[string]interface{})
+
+	var funcChildren func(string, *PageItem)
+	funcChildren = func(parentPath string, item *PageItem) {
+
+		var curPath string
+
+		if len(parentPath) == 0 {
+			curPath = fmt.Sprintf("[%s]", item.URL)
+		} else {
+			curPath = fmt.Sprintf("%s:[%s]", parentPath, item.URL)
+		}
+
+		res[curPath] = nil
+
+		for _, it := range item.Children {
+			funcChildren(curPath, it)
+		}
+	}
+
+	funcChildren("", root)
+
+	return res
+}
+
+func toLinks(urls []string) []Link {
+
+	if urls == nil {
+		return nil
+	}
+
+	res := make([]Link, 0, len(urls))
+	for _, u := range urls {
+		res = append(res, Link{URL: u})
+	}
+
+	return res
+}
+
+func TestCore_RunRobotsDirectives(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		honorNofollowLinks bool
+		res                map[string]interface{}
+		excluded           map[string]string
+	}
+
+	pages := map[string]*Page{
+		"http://start.e.com": {
+			Links: []Link{
+				{URL: "http://start.e.com/noindex"},
+				{URL: "http://start.e.com/nofollow"},
+				{URL: "http://start.e.com/rel_nofollow", NoFollow: true},
+			},
+		},
+		"http://start.e.com/noindex": {
+			NoIndex: true,
+			Links:   []Link{{URL: "http://start.e.com/noindex/link_01"}},
+		},
+		"http://start.e.com/nofollow": {
+			NoFollow: true,
+			Links:    []Link{{URL: "http://start.e.com/nofollow/link_01"}},
+		},
+	}
+
+	tests := map[string]Test{
+		"rel=nofollow is crawled": {
+			honorNofollowLinks: false,
+			res: map[string]interface{}{
+				"[http://start.e.com]":                              nil,
+				"[http://start.e.com]:[http://start.e.com/noindex]": nil,
+				"[http://start.e.com]:[http://start.e.com/noindex]:[http://start.e.com/noindex/link_01]": nil,
+				"[http://start.e.com]:[http://start.e.com/nofollow]":                                     nil,
+				"[http://start.e.com]:[http://start.e.com/rel_nofollow]":                                 nil,
+			},
+			excluded: map[string]string{
+				"http://start.e.com/noindex": "noindex",
+			},
+		},
+
+		"rel=nofollow is honored": {
+			honorNofollowLinks: true,
+			res: map[string]interface{}{
+				"[http://start.e.com]":                              nil,
+				"[http://start.e.com]:[http://start.e.com/noindex]": nil,
+				"[http://start.e.com]:[http://start.e.com/noindex]:[http://start.e.com/noindex/link_01]": nil,
+				"[http://start.e.com]:[http://start.e.com/nofollow]":                                     nil,
+			},
+			excluded: map[string]string{
+				"http://start.e.com/noindex": "noindex",
+			},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			mockCtrl := gomock.NewController(t)
+
+			mockPageLoader := NewMockPageLoader(mockCtrl)
+			mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+					if p, ok := pages[url]; ok {
+						return p, nil
+					}
+
+					return &Page{}, nil
+				})
+
+			var res map[string]interface{}
+			excluded := make(map[string]string)
+
+			mockReporter := NewMockReporter(mockCtrl)
+			mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+				res = collectPaths(root)
+
+				var walkFunc func(*PageItem)
+				walkFunc = func(item *PageItem) {
+					if len(item.ExcludeReason) > 0 {
+						excluded[item.URL] = item.ExcludeReason
+					}
+
+					for _, c := range item.Children {
+						walkFunc(c)
+					}
+				}
+
+				walkFunc(root)
+
+				return nil
+			})
+
+			cr := New(Config{
+				URL:                "http://start.e.com",
+				NWorkers:           5,
+				MaxDepth:           3,
+				HonorNofollowLinks: test.honorNofollowLinks,
+			}, mockPageLoader, mockReporter)
+
+			require.NoError(t, cr.Run(context.Background()))
+			require.Equal(t, test.res, res)
+			require.Equal(t, test.excluded, excluded)
+		})
+	}
+}
+
+func TestCore_RunCanonical(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]*Page{
+		"http://start.e.com": {
+			Links: toLinks([]string{
+				"http://start.e.com/a?utm_source=x",
+				"http://start.e.com/a/",
+				"http://start.e.com/b",
+				"http://start.e.com/c",
+			}),
+		},
+		"http://start.e.com/a?utm_source=x": {
+			Canonical: "http://start.e.com/a",
+			Links:     toLinks([]string{"http://start.e.com/a/link_01"}),
+		},
+		"http://start.e.com/a/": {
+			Canonical: "http://start.e.com/a",
+		},
+		"http://start.e.com/b": {
+			Canonical: "http://external.domain.com/b",
+		},
+		"http://start.e.com/c": {
+			Canonical: "http://start.e.com/c",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			if p, ok := pages[url]; ok {
+				return p, nil
+			}
+
+			return &Page{}, nil
+		})
+
+	var res map[string]interface{}
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		res = collectPaths(root)
+		return nil
+	})
+
+	cr := New(Config{
+		URL:      "http://start.e.com",
+		NWorkers: 1,
+		MaxDepth: 3,
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]interface{}{
+		"[http://start.e.com]":                        nil,
+		"[http://start.e.com]:[http://start.e.com/a]": nil,
+		"[http://start.e.com]:[http://start.e.com/a]:[http://start.e.com/a/link_01]": nil,
+		"[http://start.e.com]:[http://start.e.com/b]":                                nil,
+		"[http://start.e.com]:[http://start.e.com/c]":                                nil,
+	}, res)
+
+	require.Equal(t, map[string][]string{
+		"http://start.e.com/a": {"http://start.e.com/a/", "http://start.e.com/a?utm_source=x"},
+	}, cr.Variants())
+}
+
+func TestCore_RunNormalize(t *testing.T) {
+	t.Parallel()
+
+	srcLinks := map[string][]string{
+		"http://start.e.com/": {
+			"http://START.e.com:80/a/./b",
+			"http://start.e.com/a/b?utm_source=x",
+			"http://start.e.com/c?y=2&x=1",
+			"http://start.e.com/c?x=1&y=2#section",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			return &Page{Links: toLinks(srcLinks[url])}, nil
+		})
+
+	var res map[string]interface{}
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		res = collectPaths(root)
+		return nil
+	})
+
+	cr := New(Config{
+		URL:       "http://start.e.com",
+		NWorkers:  5,
+		MaxDepth:  3,
+		Normalize: true,
+		Normalization: urlnorm.Config{
+			StripParams: []string{"utm_*"},
+		},
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]interface{}{
+		"[http://start.e.com/]":                                nil,
+		"[http://start.e.com/]:[http://start.e.com/a/b]":       nil,
+		"[http://start.e.com/]:[http://start.e.com/c?x=1&y=2]": nil,
+	}, res)
+}
+
+func TestCore_RunPatterns(t *testing.T) {
+	t.Parallel()
+
+	srcLinks := map[string][]string{
+		"http://start.e.com/": {
+			"http://start.e.com/blog/",
+			"http://start.e.com/about",
+		},
+		"http://start.e.com/blog/": {
+			"http://start.e.com/blog/post-1",
+			"http://start.e.com/blog/tag/go",
+			"http://start.e.com/blog/?page=2",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			return &Page{Links: toLinks(srcLinks[url])}, nil
+		})
+
+	var res map[string]interface{}
+	var rootExcludeReason string
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		res = collectPaths(root)
+		rootExcludeReason = root.ExcludeReason
+		return nil
+	})
+
+	cr := New(Config{
+		URL:      "http://start.e.com/",
+		NWorkers: 5,
+		MaxDepth: 3,
+		Include:  []string{"/blog/**"},
+		Exclude:  []string{"/blog/tag/**", `re:[?&]page=`},
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]interface{}{
+		"[http://start.e.com/]":                            nil,
+		"[http://start.e.com/]:[http://start.e.com/blog/]": nil,
+		"[http://start.e.com/]:[http://start.e.com/blog/]:[http://start.e.com/blog/post-1]": nil,
+	}, res)
+
+	require.Equal(t, "doesn't match include patterns", rootExcludeReason)
+}
+
+func TestCore_RunBadPattern(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+
+	cr := New(Config{
+		URL:      "http://start.e.com/",
+		NWorkers: 5,
+		MaxDepth: 3,
+		Exclude:  []string{"re:[a-"},
+	}, NewMockPageLoader(mockCtrl), NewMockReporter(mockCtrl))
+
+	require.Error(t, cr.Run(context.Background()))
+}
+
+func TestCore_RunRateLimit(t *testing.T) {
+	t.Parallel()
+
+	srcLinks := map[string][]string{
+		"http://start.e.com": {
+			"http://start.e.com/link_00_01",
+			"http://start.e.com/link_00_02",
+			"http://start.e.com/link_00_03",
+		},
+	}
+
+	robotsTxt := "User-agent: *\nCrawl-delay: 0.03\n"
+
+	var inFlight, maxInFlight int32
+
+	limiter := ratelimit.New(ratelimit.Config{
+		RPS:         1000,
+		MaxInFlight: 1,
+	})
+
+	mockCtrl := gomock.NewController(t)
+
+	// the loader waits for the limiter before every request
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).Times(4).
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			release, err := limiter.Wait(ctx, "start.e.com")
+			if err != nil {
+				return nil, err
+			}
+
+			defer release()
+
+			n := atomic.AddInt32(&inFlight, 1)
+			defer atomic.AddInt32(&inFlight, -1)
+
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+
+			return &Page{Links: toLinks(srcLinks[url])}, nil
+		})
+	mockPageLoader.EXPECT().LoadRobots(gomock.Any(), "http://start.e.com/robots.txt").Times(1).
+		Return(robots.Parse(strings.NewReader(robotsTxt)))
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).Return(nil)
+
+	cr := New(Config{
+		URL:           "http://start.e.com",
+		NWorkers:      5,
+		MaxDepth:      3,
+		RespectRobots: true,
+		Limiter:       limiter,
+	}, mockPageLoader, mockReporter)
+
+	start := time.Now()
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	// Crawl-delay is longer than RPS interval, so 4 requests take at least 3 delays
+	require.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	require.Equal(t, int32(1), maxInFlight)
+}
+
+func TestCore_RunFailed(t *testing.T) {
+	t.Parallel()
+
+	srcLinks := map[string][]string{
+		"http://start.e.com": {
+			"http://start.e.com/link_00_01",
+			"http://start.e.com/link_00_02",
+		},
+		"http://start.e.com/link_00_02": {
+			"http://start.e.com/link_02_01",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			if url == "http://start.e.com/link_00_01" {
+				return nil, errors.New("failed after 3 retries: server responded with status 503")
+			}
+
+			return &Page{Links: toLinks(srcLinks[url])}, nil
+		})
+
+	var res []string
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		for _, item := range root.Children {
+			res = append(res, item.URL+" "+item.ExcludeReason)
+		}
+		return nil
+	})
+
+	cr := New(Config{
+		URL:      "http://start.e.com",
+		NWorkers: 5,
+		MaxDepth: 3,
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.ElementsMatch(t, []string{
+		"http://start.e.com/link_00_01 failed to load",
+		"http://start.e.com/link_00_02 ",
+	}, res)
+
+	require.Equal(t, map[string]string{
+		"http://start.e.com/link_00_01": "failed after 3 retries: server responded with status 503",
+	}, cr.Failed())
+}
+
+func TestCore_RunStartPageFailed(t *testing.T) {
+	t.Parallel()
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	cr := New(Config{
+		URL:      "http://start.e.com",
+		NWorkers: 5,
+		MaxDepth: 3,
+	}, mockPageLoader, NewMockReporter(mockCtrl))
+
+	require.ErrorContains(t, cr.Run(context.Background()), "connection refused")
+}
+
+func TestCore_RunStatus(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]*Page{
+		"http://start.e.com": {
+			StatusCode: 200,
+			Links:      toLinks([]string{"http://start.e.com/missing", "http://start.e.com/doc.pdf"}),
+		},
+		"http://start.e.com/missing": {
+			StatusCode: 404,
+			Links:      toLinks([]string{"http://start.e.com/link_01_01"}),
+		},
+		"http://start.e.com/doc.pdf": {
+			StatusCode:  200,
+			ContentType: "application/pdf",
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).Times(3).
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			return pages[url], nil
+		})
+
+	res := make(map[string]string)
+	statuses := make(map[string]int)
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		for _, item := range root.Children {
+			res[item.URL] = item.ExcludeReason
+			statuses[item.URL] = item.StatusCode
+		}
+		return nil
+	})
+
+	cr := New(Config{
+		URL:      "http://start.e.com",
+		NWorkers: 5,
+		MaxDepth: 3,
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]string{
+		"http://start.e.com/missing": "status 404",
+		"http://start.e.com/doc.pdf": "",
+	}, res)
+
+	require.Equal(t, map[string]int{
+		"http://start.e.com/missing": 404,
+		"http://start.e.com/doc.pdf": 200,
+	}, statuses)
+}
+
+func TestCore_RunRedirects(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]*Page{
+		"http://www.e.com/": {
+			FinalURL:  "https://www.e.com/",
+			Redirects: []string{"http://www.e.com/"},
+			Links: toLinks([]string{
+				"https://www.e.com/old",
+				"https://www.e.com/out",
+				"https://www.e.com/hop",
+			}),
+		},
+		"https://www.e.com/old": {
+			FinalURL:  "https://www.e.com/new",
+			Redirects: []string{"https://www.e.com/old"},
+			Links:     toLinks([]string{"https://www.e.com/link_01_01"}),
+		},
+		"https://www.e.com/out": {
+			FinalURL:  "https://other.com/",
+			Redirects: []string{"https://www.e.com/out"},
+			Links:     toLinks([]string{"https://other.com/link"}),
+		},
+	}
+
+	mockCtrl := gomock.NewController(t)
+
+	mockPageLoader := NewMockPageLoader(mockCtrl)
+	mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+			if url == "https://www.e.com/hop" {
+				return nil, fmt.Errorf("redirect to [https://other.com/]: %w", ErrOutOfScope)
+			}
+
+			if page, ok := pages[url]; ok {
+				return page, nil
+			}
+
+			return &Page{}, nil
+		})
+
+	var res map[string]interface{}
+
+	mockReporter := NewMockReporter(mockCtrl)
+	mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+		res = collectPaths(root)
+		return nil
+	})
+
+	cr := New(Config{
+		URL:       "http://www.e.com",
+		NWorkers:  5,
+		MaxDepth:  3,
+		Normalize: true,
+	}, mockPageLoader, mockReporter)
+
+	require.NoError(t, cr.Run(context.Background()))
+
+	require.Equal(t, map[string]interface{}{
+		"[https://www.e.com/]":                         nil,
+		"[https://www.e.com/]:[https://www.e.com/new]": nil,
+		"[https://www.e.com/]:[https://www.e.com/new]:[https://www.e.com/link_01_01]": nil,
+	}, res)
+
+	require.Equal(t, map[string]string{
+		"http://www.e.com/":     "https://www.e.com/",
+		"https://www.e.com/old": "https://www.e.com/new",
+	}, cr.Redirects())
+
+	require.Equal(t, map[string]string{
+		"https://www.e.com/out": "redirects out of scope to [https://other.com/]",
+		"https://www.e.com/hop": "redirect to [https://other.com/]: out of scope",
+	}, cr.Skipped())
+	require.Empty(t, cr.Failed())
+
+	// the start URL should be resolved by a caller if it redirects to another host
+	mockStartLoader := NewMockPageLoader(mockCtrl)
+	mockStartLoader.EXPECT().LoadPage(gomock.Any(), "http://e.com/").Times(1).
+		Return(&Page{FinalURL: "https://www.e.com/"}, nil)
+
+	cr = New(Config{
+		URL:       "http://e.com",
+		NWorkers:  5,
+		Normalize: true,
+	}, mockStartLoader, mockReporter)
+
+	require.EqualError(t, cr.Run(context.Background()), "start page was skipped: redirects out of scope to [https://www.e.com/]")
+}
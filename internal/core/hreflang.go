@@ -0,0 +1,217 @@
+package core
+
+import (
+	"fmt"
+	"log"
+	"sort"
+)
+
+// AlternateCluster is a group of pages that are localized versions of each other.
+type AlternateCluster struct {
+	// Alternates are all versions of the cluster sorted by language. Every language has a single URL.
+	Alternates []Alternate
+
+	// Problems describe why the cluster isn't reciprocal: pages that don't refer back to their alternates
+	// or to themselves, languages that have several URLs and alternates that are excluded from the sitemap.
+	Problems []string
+}
+
+// Reciprocal checks if all pages of the cluster refer to each other.
+func (c *AlternateCluster) Reciprocal() bool {
+	return len(c.Problems) == 0
+}
+
+// Has checks if the URL is one of the cluster alternates.
+func (c *AlternateCluster) Has(pageURL string) bool {
+	for _, a := range c.Alternates {
+		if a.URL == pageURL {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Clusters returns hreflang clusters of crawled pages. It should be called after Run() finishes.
+func (cr *Core) Clusters() []*AlternateCluster {
+	return cr.clusters
+}
+
+// normalizeAlternates normalizes alternate URLs the same way page URLs are normalized, so they can be matched.
+// Alternates with bad URLs are dropped.
+func (cr *Core) normalizeAlternates(alternates []Alternate, chanErr chan error) []Alternate {
+
+	res := make([]Alternate, 0, len(alternates))
+
+	for _, a := range alternates {
+		u, err := cr.normalize(a.URL)
+		if err != nil {
+			chanErr <- fmt.Errorf("loader returned a bad alternate URL [%v]: %w", a.URL, err)
+			continue
+		}
+
+		res = append(res, Alternate{Lang: a.Lang, URL: u})
+	}
+
+	return res
+}
+
+// buildClusters groups pages that refer to each other with hreflang alternates into clusters
+// and sets Cluster of every crawled page of a cluster.
+// A cluster has alternates of all its pages, so it's reciprocal in a sitemap even if pages are not.
+// Clusters that are not reciprocal on pages are logged.
+func buildClusters(root *PageItem) []*AlternateCluster {
+
+	items := make(map[string]*PageItem)
+
+	var walkFunc func(*PageItem)
+	walkFunc = func(item *PageItem) {
+		items[item.URL] = item
+
+		for _, child := range item.Children {
+			walkFunc(child)
+		}
+	}
+
+	if root != nil {
+		walkFunc(root)
+	}
+
+	parents := make(map[string]string)
+
+	var findFunc func(string) string
+	findFunc = func(u string) string {
+		p, ok := parents[u]
+		if !ok || p == u {
+			parents[u] = u
+			return u
+		}
+
+		parents[u] = findFunc(p)
+
+		return parents[u]
+	}
+
+	urls := make([]string, 0, len(items))
+	for u := range items {
+		urls = append(urls, u)
+	}
+
+	sort.Strings(urls)
+
+	for _, u := range urls {
+		for _, a := range items[u].Meta.Alternates {
+			parents[findFunc(a.URL)] = findFunc(u)
+		}
+	}
+
+	components := make(map[string][]string)
+	var roots []string
+
+	members := make([]string, 0, len(parents))
+	for u := range parents {
+		members = append(members, u)
+	}
+
+	sort.Strings(members)
+
+	for _, u := range members {
+		r := findFunc(u)
+		if _, ok := components[r]; !ok {
+			roots = append(roots, r)
+		}
+
+		components[r] = append(components[r], u)
+	}
+
+	clusters := make([]*AlternateCluster, 0, len(roots))
+
+	for _, r := range roots {
+
+		cluster := newCluster(components[r], items)
+
+		for _, u := range components[r] {
+			if item, ok := items[u]; ok {
+				item.Cluster = cluster
+			}
+		}
+
+		for _, problem := range cluster.Problems {
+			log.Printf("ERR: hreflang cluster of [%v] is not reciprocal: %v", components[r][0], problem)
+		}
+
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters
+}
+
+// newCluster builds a cluster of pages URLs. items are crawled pages by URLs.
+func newCluster(urls []string, items map[string]*PageItem) *AlternateCluster {
+
+	cluster := &AlternateCluster{}
+
+	langs := make(map[string]string)
+
+	for _, u := range urls {
+
+		item, ok := items[u]
+		if !ok || len(item.Meta.Alternates) == 0 {
+			continue
+		}
+
+		refersSelf := false
+
+		for _, a := range item.Meta.Alternates {
+
+			if a.URL == u {
+				refersSelf = true
+			}
+
+			if alt, ok := items[a.URL]; ok && a.URL != u {
+				if len(alt.ExcludeReason) > 0 {
+					cluster.Problems = append(cluster.Problems,
+						fmt.Sprintf("[%v] refers to [%v] that is excluded: %v", u, a.URL, alt.ExcludeReason))
+					continue
+				}
+
+				if !refersTo(alt, u) {
+					cluster.Problems = append(cluster.Problems, fmt.Sprintf("[%v] doesn't refer back to [%v]", a.URL, u))
+				}
+			}
+
+			if existing, ok := langs[a.Lang]; ok {
+				if existing != a.URL {
+					cluster.Problems = append(cluster.Problems,
+						fmt.Sprintf("[%v] has URLs [%v] and [%v]", a.Lang, existing, a.URL))
+				}
+
+				continue
+			}
+
+			langs[a.Lang] = a.URL
+			cluster.Alternates = append(cluster.Alternates, a)
+		}
+
+		if !refersSelf {
+			cluster.Problems = append(cluster.Problems, fmt.Sprintf("[%v] doesn't refer to itself", u))
+		}
+	}
+
+	sort.Slice(cluster.Alternates, func(i, j int) bool {
+		return cluster.Alternates[i].Lang < cluster.Alternates[j].Lang
+	})
+
+	return cluster
+}
+
+// refersTo checks if the page has an alternate with the URL.
+func refersTo(item *PageItem, pageURL string) bool {
+	for _, a := range item.Meta.Alternates {
+		if a.URL == pageURL {
+			return true
+		}
+	}
+
+	return false
+}
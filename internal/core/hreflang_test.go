@@ -0,0 +1,141 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_buildClusters(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		root        *PageItem
+		expClusters []*AlternateCluster
+	}
+
+	alternates := func(pairs ...string) PageMeta {
+		var meta PageMeta
+		for i := 0; i < len(pairs); i += 2 {
+			meta.Alternates = append(meta.Alternates, Alternate{Lang: pairs[i], URL: pairs[i+1]})
+		}
+
+		return meta
+	}
+
+	tests := map[string]Test{
+		"reciprocal": {
+			root: &PageItem{
+				URL:  "http://e.com/",
+				Meta: alternates("x-default", "http://e.com/", "en", "http://e.com/en", "de", "http://e.com/de"),
+				Children: []*PageItem{
+					{URL: "http://e.com/en", Meta: alternates("x-default", "http://e.com/", "en", "http://e.com/en", "de", "http://e.com/de")},
+					{URL: "http://e.com/de", Meta: alternates("x-default", "http://e.com/", "en", "http://e.com/en", "de", "http://e.com/de")},
+					{URL: "http://e.com/about"},
+				},
+			},
+			expClusters: []*AlternateCluster{
+				{Alternates: []Alternate{
+					{Lang: "de", URL: "http://e.com/de"},
+					{Lang: "en", URL: "http://e.com/en"},
+					{Lang: "x-default", URL: "http://e.com/"},
+				}},
+			},
+		},
+
+		"not reciprocal": {
+			root: &PageItem{
+				URL:  "http://e.com/",
+				Meta: alternates("en", "http://e.com/", "de", "http://e.com/de", "fr", "http://e.com/fr"),
+				Children: []*PageItem{
+					{URL: "http://e.com/de", Meta: alternates("de", "http://e.com/de", "en", "http://e.com/en")},
+					{URL: "http://e.com/fr"},
+				},
+			},
+			expClusters: []*AlternateCluster{
+				{
+					Alternates: []Alternate{
+						{Lang: "de", URL: "http://e.com/de"},
+						{Lang: "en", URL: "http://e.com/"},
+						{Lang: "fr", URL: "http://e.com/fr"},
+					},
+					Problems: []string{
+						"[http://e.com/de] doesn't refer back to [http://e.com/]",
+						"[http://e.com/fr] doesn't refer back to [http://e.com/]",
+						"[en] has URLs [http://e.com/] and [http://e.com/en]",
+					},
+				},
+			},
+		},
+
+		"separate clusters": {
+			root: &PageItem{
+				URL:  "http://e.com/",
+				Meta: alternates("en", "http://e.com/", "de", "http://e.com/de"),
+				Children: []*PageItem{
+					{URL: "http://e.com/de", Meta: alternates("en", "http://e.com/", "de", "http://e.com/de")},
+					{URL: "http://e.com/blog", Meta: alternates("en", "http://e.com/blog", "de", "http://e.com/de/blog")},
+					{URL: "http://e.com/de/blog", ExcludeReason: "noindex"},
+					{URL: "http://e.com/news", Meta: alternates("de", "http://e.com/de/news")},
+				},
+			},
+			expClusters: []*AlternateCluster{
+				{
+					Alternates: []Alternate{
+						{Lang: "de", URL: "http://e.com/de"},
+						{Lang: "en", URL: "http://e.com/"},
+					},
+				},
+				{
+					Alternates: []Alternate{
+						{Lang: "en", URL: "http://e.com/blog"},
+					},
+					Problems: []string{
+						"[http://e.com/blog] refers to [http://e.com/de/blog] that is excluded: noindex",
+					},
+				},
+				{
+					Alternates: []Alternate{
+						{Lang: "de", URL: "http://e.com/de/news"},
+					},
+					Problems: []string{
+						"[http://e.com/news] doesn't refer to itself",
+					},
+				},
+			},
+		},
+
+		"no alternates": {
+			root:        &PageItem{URL: "http://e.com/", Children: []*PageItem{{URL: "http://e.com/about"}}},
+			expClusters: []*AlternateCluster{},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			clusters := buildClusters(test.root)
+
+			require.Equal(t, test.expClusters, clusters)
+
+			var walkFunc func(*PageItem)
+			walkFunc = func(item *PageItem) {
+				for _, c := range clusters {
+					if c.Has(item.URL) {
+						require.Same(t, c, item.Cluster)
+					}
+				}
+
+				for _, child := range item.Children {
+					walkFunc(child)
+				}
+			}
+
+			walkFunc(test.root)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
+	robots "github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
 )
 
 // MockPageLoader is a mock of PageLoader interface.
@@ -34,19 +35,49 @@ func (m *MockPageLoader) EXPECT() *MockPageLoaderMockRecorder {
 	return m.recorder
 }
 
-// GetPageLinks mocks base method.
-func (m *MockPageLoader) GetPageLinks(arg0 context.Context, arg1 string) ([]string, error) {
+// LoadPage mocks base method.
+func (m *MockPageLoader) LoadPage(arg0 context.Context, arg1 string) (*Page, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPageLinks", arg0, arg1)
-	ret0, _ := ret[0].([]string)
+	ret := m.ctrl.Call(m, "LoadPage", arg0, arg1)
+	ret0, _ := ret[0].(*Page)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPageLinks indicates an expected call of GetPageLinks.
-func (mr *MockPageLoaderMockRecorder) GetPageLinks(arg0, arg1 interface{}) *gomock.Call {
+// LoadPage indicates an expected call of LoadPage.
+func (mr *MockPageLoaderMockRecorder) LoadPage(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPageLinks", reflect.TypeOf((*MockPageLoader)(nil).GetPageLinks), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadPage", reflect.TypeOf((*MockPageLoader)(nil).LoadPage), arg0, arg1)
+}
+
+// LoadRobots mocks base method.
+func (m *MockPageLoader) LoadRobots(arg0 context.Context, arg1 string) (*robots.Robots, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadRobots", arg0, arg1)
+	ret0, _ := ret[0].(*robots.Robots)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadRobots indicates an expected call of LoadRobots.
+func (mr *MockPageLoaderMockRecorder) LoadRobots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadRobots", reflect.TypeOf((*MockPageLoader)(nil).LoadRobots), arg0, arg1)
+}
+
+// LoadSitemap mocks base method.
+func (m *MockPageLoader) LoadSitemap(arg0 context.Context, arg1 string) (*Sitemap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadSitemap", arg0, arg1)
+	ret0, _ := ret[0].(*Sitemap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadSitemap indicates an expected call of LoadSitemap.
+func (mr *MockPageLoaderMockRecorder) LoadSitemap(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadSitemap", reflect.TypeOf((*MockPageLoader)(nil).LoadSitemap), arg0, arg1)
 }
 
 // MockReporter is a mock of Reporter interface.
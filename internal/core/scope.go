@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ScopeMode defines which hosts belong to the crawled site.
+type ScopeMode int
+
+const (
+	// ScopeHost is the start page host only.
+	ScopeHost ScopeMode = iota
+
+	// ScopeWWW treats www.example.com and example.com as the same host.
+	ScopeWWW
+
+	// ScopeSubdomains includes all subdomains of the start page registrable domain (eTLD+1 by public suffix list).
+	ScopeSubdomains
+
+	// ScopeAllowlist is the start page host and Config.AllowedHosts.
+	ScopeAllowlist
+)
+
+const wwwPrefix = "www."
+
+// ErrOutOfScope is returned by PageLoader if a page redirects out of the crawl scope. Such pages are skipped.
+var ErrOutOfScope = errors.New("out of scope")
+
+// ParseScopeMode parses scope mode name: host, www, subdomains or allowlist.
+func ParseScopeMode(s string) (ScopeMode, error) {
+	switch strings.ToLower(s) {
+	case "", "host":
+		return ScopeHost, nil
+	case "www":
+		return ScopeWWW, nil
+	case "subdomains":
+		return ScopeSubdomains, nil
+	case "allowlist":
+		return ScopeAllowlist, nil
+	default:
+		return ScopeHost, fmt.Errorf("unknown scope mode [%v]", s)
+	}
+}
+
+// Scope checks if URLs belong to the crawled site. Host is the start page host.
+type Scope struct {
+	Mode         ScopeMode
+	Host         string
+	AllowedHosts []string
+}
+
+// Contains checks if a URL host belongs to the scope.
+func (s Scope) Contains(u *url.URL) bool {
+
+	host := strings.ToLower(u.Hostname())
+	rootHost := strings.ToLower(s.Host)
+
+	if host == rootHost {
+		return true
+	}
+
+	switch s.Mode {
+
+	case ScopeWWW:
+		return strings.TrimPrefix(host, wwwPrefix) == strings.TrimPrefix(rootHost, wwwPrefix)
+
+	case ScopeSubdomains:
+		domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+		if err != nil {
+			return false
+		}
+
+		rootDomain, err := publicsuffix.EffectiveTLDPlusOne(rootHost)
+		if err != nil {
+			return false
+		}
+
+		return domain == rootDomain
+
+	case ScopeAllowlist:
+		for _, h := range s.AllowedHosts {
+			if strings.EqualFold(h, host) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// inScope checks if a URL belongs to the crawled site according to Config.Scope.
+func (cr *Core) inScope(u *url.URL) bool {
+	return Scope{
+		Mode:         cr.config.Scope,
+		Host:         cr.rootDomain,
+		AllowedHosts: cr.config.AllowedHosts,
+	}.Contains(u)
+}
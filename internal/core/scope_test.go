@@ -0,0 +1,68 @@
+package core
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCore_inScope(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		mode         ScopeMode
+		rootDomain   string
+		allowedHosts []string
+		url          string
+		expRes       bool
+	}
+
+	tests := map[string]Test{
+		"host":                 {mode: ScopeHost, rootDomain: "e.com", url: "http://e.com/a", expRes: true},
+		"host case":            {mode: ScopeHost, rootDomain: "e.com", url: "http://E.com/a", expRes: true},
+		"host www":             {mode: ScopeHost, rootDomain: "e.com", url: "http://www.e.com/a", expRes: false},
+		"www":                  {mode: ScopeWWW, rootDomain: "e.com", url: "http://www.e.com/a", expRes: true},
+		"www reverse":          {mode: ScopeWWW, rootDomain: "www.e.com", url: "http://e.com/a", expRes: true},
+		"www subdomain":        {mode: ScopeWWW, rootDomain: "e.com", url: "http://blog.e.com/a", expRes: false},
+		"subdomains":           {mode: ScopeSubdomains, rootDomain: "www.e.com", url: "http://blog.e.com/a", expRes: true},
+		"subdomains deep":      {mode: ScopeSubdomains, rootDomain: "e.co.uk", url: "http://a.b.e.co.uk/a", expRes: true},
+		"subdomains other":     {mode: ScopeSubdomains, rootDomain: "e.co.uk", url: "http://other.co.uk/a", expRes: false},
+		"subdomains suffix":    {mode: ScopeSubdomains, rootDomain: "a.github.io", url: "http://b.github.io/a", expRes: false},
+		"subdomains localhost": {mode: ScopeSubdomains, rootDomain: "localhost", url: "http://127.0.0.1/a", expRes: false},
+		"allowlist":            {mode: ScopeAllowlist, rootDomain: "e.com", allowedHosts: []string{"Blog.e.com"}, url: "http://blog.e.com/a", expRes: true},
+		"allowlist root":       {mode: ScopeAllowlist, rootDomain: "e.com", allowedHosts: []string{"blog.e.com"}, url: "http://e.com/a", expRes: true},
+		"allowlist other":      {mode: ScopeAllowlist, rootDomain: "e.com", allowedHosts: []string{"blog.e.com"}, url: "http://shop.e.com/a", expRes: false},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			cr := New(Config{
+				Scope:        test.mode,
+				AllowedHosts: test.allowedHosts,
+			}, nil, nil)
+			cr.rootDomain = test.rootDomain
+
+			u, err := url.Parse(test.url)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expRes, cr.inScope(u))
+		})
+	}
+}
+
+func TestParseScopeMode(t *testing.T) {
+	t.Parallel()
+
+	mode, err := ParseScopeMode("Subdomains")
+	require.NoError(t, err)
+	require.Equal(t, ScopeSubdomains, mode)
+
+	_, err = ParseScopeMode("everything")
+	require.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"log"
+	"net/url"
+)
+
+// MaxSeedSitemaps limits a number of sitemaps that are read to collect seeds.
+const MaxSeedSitemaps = 1000
+
+// collectSeeds reads sitemaps from robots.txt and from config and returns normalized URLs of the root domain
+// that match Include and Exclude patterns.
+// Sitemap indexes are read recursively, every sitemap is read once.
+func (cr *Core) collectSeeds(ctx context.Context, rootURL *url.URL) []string {
+
+	sitemaps := append([]string(nil), cr.config.Sitemaps...)
+
+	hr := cr.getHostRobots(ctx, rootURL)
+	if hr.err != nil {
+		log.Printf("ERR: failed to get sitemaps from robots.txt: %v", hr.err)
+	} else {
+		sitemaps = append(sitemaps, hr.sitemaps...)
+	}
+
+	visited := make(map[string]interface{})
+	seen := make(map[string]interface{})
+
+	var seeds []string
+
+	for len(sitemaps) > 0 && len(visited) < MaxSeedSitemaps {
+
+		sitemapURL := sitemaps[0]
+		sitemaps = sitemaps[1:]
+
+		if _, ok := visited[sitemapURL]; ok {
+			continue
+		}
+
+		visited[sitemapURL] = nil
+
+		sm, err := cr.pageLoader.LoadSitemap(ctx, sitemapURL)
+		if err != nil {
+			log.Printf("ERR: failed to load sitemap [%v]: %v", sitemapURL, err)
+			continue
+		}
+
+		sitemaps = append(sitemaps, sm.Sitemaps...)
+
+		for _, rawLink := range sm.URLs {
+
+			link, err := cr.normalize(rawLink)
+			if err != nil {
+				log.Printf("ERR: sitemap [%v] has a bad URL [%v]: %v", sitemapURL, rawLink, err)
+				continue
+			}
+
+			if _, ok := seen[link]; ok {
+				continue
+			}
+
+			seen[link] = nil
+
+			u, err := url.ParseRequestURI(link)
+			if err != nil || !cr.inScope(u) {
+				continue
+			}
+
+			if ok, _ := cr.matchPatterns(u); !ok {
+				continue
+			}
+
+			seeds = append(seeds, link)
+		}
+	}
+
+	if len(sitemaps) > 0 {
+		log.Printf("ERR: sitemaps limit %d is reached, %d sitemaps are not read", MaxSeedSitemaps, len(sitemaps))
+	}
+
+	log.Printf("%d seed pages are collected from %d sitemaps", len(seeds), len(visited))
+
+	return seeds
+}
+
+// pushSeeds pushes seed tasks with the root page as a parent. It returns a number of pushed tasks.
+func (cr *Core) pushSeeds(root *PageItem) int {
+
+	if cr.config.SeedLevel > cr.config.MaxDepth {
+		return 0
+	}
+
+	for _, seed := range cr.seeds {
+		cr.tasksQueue.Push(Task{
+			level:  cr.config.SeedLevel,
+			url:    seed,
+			parent: root,
+		})
+	}
+
+	return len(cr.seeds)
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
+)
+
+func TestCore_RunSeeds(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		seedLevel int
+		maxDepth  int
+		res       map[string]interface{}
+	}
+
+	srcLinks := map[string][]string{
+		"http://start.e.com": {
+			"http://start.e.com/link_00_01",
+		},
+		"http://start.e.com/seed_01": {
+			"http://start.e.com/link_01_01",
+		},
+	}
+
+	sitemaps := map[string]*Sitemap{
+		"http://start.e.com/sitemap.xml": {
+			Sitemaps: []string{
+				"http://start.e.com/sitemap-1.xml.gz",
+				"http://start.e.com/sitemap.xml", // loop
+				"http://start.e.com/broken.xml",
+			},
+		},
+		"http://start.e.com/sitemap-1.xml.gz": {
+			URLs: []string{
+				"http://start.e.com/seed_01",
+				"http://start.e.com/link_00_01", // duplicate
+				"http://external.domain.com/seed",
+			},
+		},
+		"http://start.e.com/extra.xml": {
+			URLs: []string{
+				"http://start.e.com/seed_02",
+			},
+		},
+	}
+
+	tests := map[string]Test{
+		"Level 1": {
+			seedLevel: 1,
+			maxDepth:  2,
+			res: map[string]interface{}{
+				"[http://start.e.com]":                                                              nil,
+				"[http://start.e.com]:[http://start.e.com/link_00_01]":                              nil,
+				"[http://start.e.com]:[http://start.e.com/seed_01]":                                 nil,
+				"[http://start.e.com]:[http://start.e.com/seed_01]:[http://start.e.com/link_01_01]": nil,
+				"[http://start.e.com]:[http://start.e.com/seed_02]":                                 nil,
+			},
+		},
+
+		"Level 1 out of max depth": {
+			seedLevel: 1,
+			maxDepth:  1,
+			res: map[string]interface{}{
+				"[http://start.e.com]":                                 nil,
+				"[http://start.e.com]:[http://start.e.com/link_00_01]": nil,
+				"[http://start.e.com]:[http://start.e.com/seed_01]":    nil,
+				"[http://start.e.com]:[http://start.e.com/seed_02]":    nil,
+			},
+		},
+
+		"Level 0": {
+			seedLevel: 0,
+			maxDepth:  1,
+			res: map[string]interface{}{
+				"[http://start.e.com]":                                                              nil,
+				"[http://start.e.com]:[http://start.e.com/link_00_01]":                              nil,
+				"[http://start.e.com]:[http://start.e.com/seed_01]":                                 nil,
+				"[http://start.e.com]:[http://start.e.com/seed_01]:[http://start.e.com/link_01_01]": nil,
+				"[http://start.e.com]:[http://start.e.com/seed_02]":                                 nil,
+			},
+		},
+	}
+
+	robotsTxt := "User-agent: *\nSitemap: http://start.e.com/sitemap.xml\n"
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			mockCtrl := gomock.NewController(t)
+
+			mockPageLoader := NewMockPageLoader(mockCtrl)
+			mockPageLoader.EXPECT().LoadPage(gomock.Any(), gomock.Any()).AnyTimes().
+				DoAndReturn(func(ctx context.Context, url string) (*Page, error) {
+					return &Page{Links: toLinks(srcLinks[url])}, nil
+				})
+			mockPageLoader.EXPECT().LoadRobots(gomock.Any(), "http://start.e.com/robots.txt").Times(1).
+				Return(robots.Parse(strings.NewReader(robotsTxt)))
+			mockPageLoader.EXPECT().LoadSitemap(gomock.Any(), gomock.Any()).Times(4).
+				DoAndReturn(func(ctx context.Context, url string) (*Sitemap, error) {
+					sm, ok := sitemaps[url]
+					if !ok {
+						return nil, errors.New("not found")
+					}
+
+					return sm, nil
+				})
+
+			var res map[string]interface{}
+
+			mockReporter := NewMockReporter(mockCtrl)
+			mockReporter.EXPECT().Save(gomock.Any()).Times(1).DoAndReturn(func(root *PageItem) error {
+				res = collectPaths(root)
+				return nil
+			})
+
+			cr := New(Config{
+				URL:          "http://start.e.com",
+				NWorkers:     5,
+				MaxDepth:     test.maxDepth,
+				SeedSitemaps: true,
+				Sitemaps:     []string{"http://start.e.com/extra.xml"},
+				SeedLevel:    test.seedLevel,
+			}, mockPageLoader, mockReporter)
+
+			require.NoError(t, cr.Run(context.Background()))
+			require.Equal(t, test.res, res)
+		})
+	}
+}
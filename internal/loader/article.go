@@ -0,0 +1,152 @@
+package loader
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"golang.org/x/net/html"
+)
+
+const (
+	TagHTML = "html"
+
+	AttrLang = "lang"
+
+	PropertyPublishedTime = "article:published_time"
+	PropertyOGSiteName    = "og:site_name"
+	PropertyOGLocale      = "og:locale"
+
+	TypeBlogPosting = "BlogPosting"
+
+	KeyDatePublished = "datePublished"
+	KeyHeadline      = "headline"
+	KeyInLanguage    = "inLanguage"
+	KeyPublisher     = "publisher"
+)
+
+// getArticle returns article metadata of the page or nil if the page has no publication date.
+// The publication date is taken from <meta property="article:published_time"> or JSON-LD datePublished
+// of an Article (NewsArticle, BlogPosting, ...), the title from JSON-LD headline, og:title or <title>,
+// the language from JSON-LD inLanguage, <html lang> or og:locale,
+// and the publication name from JSON-LD publisher name or og:site_name.
+func getArticle(node *html.Node) *core.Article {
+
+	if node == nil {
+		return nil
+	}
+
+	var meta, ld core.Article
+	var pageTitle, locale string
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode {
+
+			switch n.Data {
+
+			case TagHTML:
+				meta.Language = strings.TrimSpace(getAttr(n, AttrLang))
+
+			case TagTitle:
+				if n.FirstChild != nil && len(pageTitle) == 0 {
+					pageTitle = strings.TrimSpace(n.FirstChild.Data)
+				}
+
+			case TagMeta:
+				content := strings.TrimSpace(getAttr(n, AttrContent))
+
+				switch getAttr(n, AttrProperty) {
+				case PropertyPublishedTime:
+					if meta.PublishedTime.IsZero() {
+						meta.PublishedTime = parseDate(content)
+					}
+				case PropertyOGTitle:
+					meta.Title = content
+				case PropertyOGSiteName:
+					meta.PublicationName = content
+				case PropertyOGLocale:
+					locale = strings.ReplaceAll(content, "_", "-")
+				}
+
+			case TagScript:
+				if getAttr(n, AttrType) == TypeJSONLD && n.FirstChild != nil && ld.PublishedTime.IsZero() {
+					ld = getJSONLDArticle(n.FirstChild.Data)
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	article := core.Article{
+		PublishedTime:   meta.PublishedTime,
+		Title:           firstNonEmpty(ld.Title, meta.Title, pageTitle),
+		Language:        firstNonEmpty(ld.Language, meta.Language, locale),
+		PublicationName: firstNonEmpty(ld.PublicationName, meta.PublicationName),
+	}
+
+	if article.PublishedTime.IsZero() {
+		article.PublishedTime = ld.PublishedTime
+	}
+
+	if article.PublishedTime.IsZero() {
+		return nil
+	}
+
+	return &article
+}
+
+// getJSONLDArticle returns metadata of the first Article of JSON-LD document that has datePublished.
+// A document may be an object, an array of objects or an object with @graph.
+func getJSONLDArticle(src string) core.Article {
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(src), &doc); err != nil {
+		return core.Article{}
+	}
+
+	var findFunc func(interface{}) core.Article
+	findFunc = func(v interface{}) core.Article {
+
+		switch val := v.(type) {
+
+		case []interface{}:
+			for _, item := range val {
+				if a := findFunc(item); !a.PublishedTime.IsZero() {
+					return a
+				}
+			}
+
+		case map[string]interface{}:
+			if t := jsonLDString(val[KeyType]); strings.HasSuffix(t, "Article") || t == TypeBlogPosting {
+				a := core.Article{
+					PublishedTime: parseDate(jsonLDString(val[KeyDatePublished])),
+					Title:         jsonLDString(val[KeyHeadline]),
+					Language:      jsonLDString(val[KeyInLanguage]),
+				}
+
+				if publisher, ok := val[KeyPublisher].(map[string]interface{}); ok {
+					a.PublicationName = jsonLDString(publisher[KeyName])
+				}
+
+				if !a.PublishedTime.IsZero() {
+					return a
+				}
+			}
+
+			if graph, ok := val[KeyGraph]; ok {
+				return findFunc(graph)
+			}
+		}
+
+		return core.Article{}
+	}
+
+	return findFunc(doc)
+}
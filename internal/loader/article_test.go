@@ -0,0 +1,97 @@
+package loader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_getArticle(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		page       string
+		expArticle *core.Article
+	}
+
+	tests := map[string]Test{
+		"meta tags": {
+			page: `<html lang="en-US"><head>
+<title>Page title</title>
+<meta property="og:title" content="OG title">
+<meta property="og:site_name" content="E News">
+<meta property="article:published_time" content="2022-05-06T10:00:00+02:00">
+</head></html>`,
+			expArticle: &core.Article{
+				PublishedTime:   time.Date(2022, 5, 6, 10, 0, 0, 0, time.FixedZone("", 2*60*60)),
+				Title:           "OG title",
+				Language:        "en-US",
+				PublicationName: "E News",
+			},
+		},
+
+		"JSON-LD": {
+			page: `<html><head>
+<title>Page title</title>
+<meta property="og:locale" content="de_DE">
+<script type="application/ld+json">
+{"@graph": [
+	{"@type": "WebSite", "name": "E"},
+	{"@type": "NewsArticle", "headline": "Headline", "datePublished": "2022-05-06",
+		"inLanguage": "de", "publisher": {"@type": "Organization", "name": "E Zeitung"}}
+]}
+</script>
+</head></html>`,
+			expArticle: &core.Article{
+				PublishedTime:   time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC),
+				Title:           "Headline",
+				Language:        "de",
+				PublicationName: "E Zeitung",
+			},
+		},
+
+		"page fallbacks": {
+			page: `<html><head>
+<title>Page title</title>
+<meta property="og:locale" content="fr_FR">
+<script type="application/ld+json">{"@type": "BlogPosting", "datePublished": "2022-05-06T10:00:00Z"}</script>
+</head></html>`,
+			expArticle: &core.Article{
+				PublishedTime: time.Date(2022, 5, 6, 10, 0, 0, 0, time.UTC),
+				Title:         "Page title",
+				Language:      "fr-FR",
+			},
+		},
+
+		"not an article": {
+			page: `<html lang="en"><head><title>About</title>
+<script type="application/ld+json">{"@type": "Event", "datePublished": "2022-05-06"}</script>
+</head></html>`,
+			expArticle: nil,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			article := getArticle(parsePage([]byte(test.page)))
+
+			if test.expArticle == nil {
+				require.Nil(t, article)
+				return
+			}
+
+			require.NotNil(t, article)
+			require.True(t, test.expArticle.PublishedTime.Equal(article.PublishedTime))
+
+			article.PublishedTime = test.expArticle.PublishedTime
+			require.Equal(t, test.expArticle, article)
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package loader
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+const (
+	HeaderAuthorization = "Authorization"
+	HeaderCookie        = "Cookie"
+
+	TagForm  = "form"
+	TagInput = "input"
+
+	AttrAction = "action"
+	AttrMethod = "method"
+	AttrValue  = "value"
+
+	InputPassword = "password"
+
+	// httpOnlyPrefix marks HttpOnly cookies in Netscape cookies files
+	httpOnlyPrefix = "#HttpOnly_"
+
+	// nCookieFields is a number of tab-separated fields of a Netscape cookies file line:
+	// domain, include subdomains, path, secure, expiration time, name, value
+	nCookieFields = 7
+)
+
+type (
+
+	// FormLogin is a login form that is submitted before the crawl.
+	// The page at URL is loaded first, so its cookies and hidden form inputs (like CSRF tokens) are kept.
+	// Then the form that has a password input (or the first form) is submitted with Fields.
+	// If the page has no form Fields are posted to URL.
+	FormLogin struct {
+		URL    string
+		Fields url.Values
+	}
+
+	// scopedJar is a cookie jar that returns cookies for URLs in scope only
+	scopedJar struct {
+		jar     http.CookieJar
+		inScope func(*url.URL) bool
+	}
+)
+
+func (j *scopedJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *scopedJar) Cookies(u *url.URL) []*http.Cookie {
+	if j.inScope != nil && !j.inScope(u) {
+		return nil
+	}
+
+	return j.jar.Cookies(u)
+}
+
+// newCookieJar creates a cookie jar that is filled from a Netscape cookies file if it's set.
+func newCookieJar(cookieFile string, inScope func(*url.URL) bool) (http.CookieJar, error) {
+
+	jar, err := cookiejar.New(&cookiejar.Options{PublicSuffixList: publicsuffix.List})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	if len(cookieFile) > 0 {
+		f, err := os.Open(cookieFile) //nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("failed to open cookies file: %w", err)
+		}
+
+		defer func() { _ = f.Close() }()
+
+		if err := loadCookies(jar, f); err != nil {
+			return nil, fmt.Errorf("failed to read cookies file [%v]: %w", cookieFile, err)
+		}
+	}
+
+	return &scopedJar{
+		jar:     jar,
+		inScope: inScope,
+	}, nil
+}
+
+// loadCookies reads cookies in Netscape format and puts them to the jar.
+// Expired cookies are ignored by the jar.
+func loadCookies(jar http.CookieJar, r io.Reader) error {
+
+	scanner := bufio.NewScanner(r)
+
+	for nLine := 1; scanner.Scan(); nLine++ {
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		httpOnly := strings.HasPrefix(line, httpOnlyPrefix)
+		if httpOnly {
+			line = strings.TrimPrefix(line, httpOnlyPrefix)
+		}
+
+		if len(strings.TrimSpace(line)) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != nCookieFields {
+			return fmt.Errorf("line %d has %d fields instead of %d", nLine, len(fields), nCookieFields)
+		}
+
+		domain, includeSubdomains, path, secure := fields[0], fields[1], fields[2], fields[3]
+
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			return fmt.Errorf("line %d has bad expiration time [%v]", nLine, fields[4])
+		}
+
+		cookie := http.Cookie{
+			Name:     fields[5],
+			Value:    fields[6],
+			Path:     path,
+			Secure:   strings.EqualFold(secure, "TRUE"),
+			HttpOnly: httpOnly,
+		}
+
+		// a domain cookie is sent to subdomains, a host-only cookie has no domain
+		if strings.EqualFold(includeSubdomains, "TRUE") {
+			cookie.Domain = domain
+		}
+
+		// zero expiration time is a session cookie
+		if expires > 0 {
+			cookie.Expires = time.Unix(expires, 0)
+		}
+
+		scheme := "http"
+		if cookie.Secure {
+			scheme = "https"
+		}
+
+		jar.SetCookies(&url.URL{Scheme: scheme, Host: strings.TrimPrefix(domain, "."), Path: path}, []*http.Cookie{&cookie})
+	}
+
+	return scanner.Err()
+}
+
+// setCredentials sets auth header and cookies to a request if it's sent to AuthScope, otherwise it removes them.
+func (l *Loader) setCredentials(req *http.Request) {
+
+	if l.config.AuthScope != nil && !l.config.AuthScope(req.URL) {
+		req.Header.Del(HeaderAuthorization)
+		req.Header.Del(HeaderCookie)
+
+		return
+	}
+
+	switch {
+	case len(l.config.BearerToken) > 0:
+		req.Header.Set(HeaderAuthorization, "Bearer "+l.config.BearerToken)
+
+	case len(l.config.BasicUser) > 0:
+		req.SetBasicAuth(l.config.BasicUser, l.config.BasicPassword)
+	}
+
+	if len(l.config.Cookies) > 0 {
+		req.Header.Del(HeaderCookie)
+
+		for _, c := range l.config.Cookies {
+			req.AddCookie(c)
+		}
+	}
+}
+
+// Login submits the login form if it's configured. Session cookies are kept in the cookie jar.
+// Login fails if the form responds with non-2xx status.
+func (l *Loader) Login(ctx context.Context) error {
+
+	if l.config.Login == nil {
+		return nil
+	}
+
+	loginURL := l.config.Login.URL
+
+	resp, err := l.doGet(ctx, loginURL, l.config.MaxBodySize, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load login page: %w", err)
+	}
+
+	if resp.statusCode < 200 || resp.statusCode >= 300 {
+		return fmt.Errorf("login page [%v] responded with status %v", loginURL, resp.statusCode)
+	}
+
+	pageURL := loginURL
+	if len(resp.finalURL) > 0 {
+		pageURL = resp.finalURL
+	}
+
+	action, method, fields := getLoginForm(parsePage(resp.body), pageURL)
+
+	for name, values := range l.config.Login.Fields {
+		fields[name] = values
+	}
+
+	var req *http.Request
+
+	if strings.EqualFold(method, http.MethodGet) {
+		u, err := url.Parse(action)
+		if err != nil {
+			return fmt.Errorf("bad login form action [%v]: %w", action, err)
+		}
+
+		u.RawQuery = fields.Encode()
+
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return fmt.Errorf("failed to create login request: %w", err)
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, action, strings.NewReader(fields.Encode()))
+		if err != nil {
+			return fmt.Errorf("failed to create login request: %w", err)
+		}
+
+		req.Header.Set(HeaderContentType, "application/x-www-form-urlencoded")
+	}
+
+	resp, err = l.do(req, l.config.MaxBodySize, nil)
+	if err != nil {
+		return fmt.Errorf("failed to submit login form: %w", err)
+	}
+
+	if resp.statusCode < 200 || resp.statusCode >= 300 {
+		return fmt.Errorf("login form [%v] responded with status %v", action, resp.statusCode)
+	}
+
+	return nil
+}
+
+// getLoginForm returns an absolute action URL, a method and input values of the form that has a password input.
+// If there's no such form the first one is used. If the page has no forms the page URL and POST are returned.
+func getLoginForm(node *html.Node, pageURL string) (string, string, url.Values) {
+
+	var forms []*html.Node
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == TagForm {
+			forms = append(forms, n)
+			return
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	if node != nil {
+		walkFunc(node)
+	}
+
+	if len(forms) == 0 {
+		return pageURL, http.MethodPost, url.Values{}
+	}
+
+	form := forms[0]
+
+	var inputs []*html.Node
+
+	for _, f := range forms {
+		fInputs := getFormInputs(f)
+
+		hasPassword := false
+		for _, input := range fInputs {
+			if strings.EqualFold(getAttr(input, AttrType), InputPassword) {
+				hasPassword = true
+				break
+			}
+		}
+
+		if hasPassword {
+			form, inputs = f, fInputs
+			break
+		}
+	}
+
+	if inputs == nil {
+		inputs = getFormInputs(form)
+	}
+
+	fields := url.Values{}
+
+	for _, input := range inputs {
+		name := getAttr(input, AttrName)
+		if len(name) == 0 {
+			continue
+		}
+
+		switch strings.ToLower(getAttr(input, AttrType)) {
+		case "submit", "button", "image", "reset", "file", "checkbox", "radio":
+			continue
+		}
+
+		fields.Add(name, getAttr(input, AttrValue))
+	}
+
+	action := pageURL
+	if href := strings.TrimSpace(getAttr(form, AttrAction)); len(href) > 0 {
+		if base, err := url.Parse(pageURL); err == nil {
+			if u, err := base.Parse(href); err == nil {
+				action = u.String()
+			}
+		}
+	}
+
+	method := http.MethodPost
+	if strings.EqualFold(getAttr(form, AttrMethod), http.MethodGet) {
+		method = http.MethodGet
+	}
+
+	return action, method, fields
+}
+
+// getFormInputs returns all <input> nodes of the form.
+func getFormInputs(form *html.Node) []*html.Node {
+
+	var inputs []*html.Node
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == TagInput {
+			inputs = append(inputs, n)
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(form)
+
+	return inputs
+}
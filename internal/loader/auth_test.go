@@ -0,0 +1,262 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_Credentials(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		config       Config
+		expAuth      string
+		expCookie    string
+		expOutCookie string
+	}
+
+	tests := map[string]Test{
+		"basic": {
+			config:  Config{BasicUser: "user", BasicPassword: "pass"},
+			expAuth: "Basic dXNlcjpwYXNz",
+		},
+
+		"bearer": {
+			config:  Config{BearerToken: "token"},
+			expAuth: "Bearer token",
+		},
+
+		"cookies": {
+			config:    Config{Cookies: []*http.Cookie{{Name: "session", Value: "abc"}}},
+			expCookie: "session=abc",
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			var outHeader http.Header
+
+			outServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				outHeader = r.Header.Clone()
+			}))
+			defer outServer.Close()
+
+			var header http.Header
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/out" {
+					http.Redirect(w, r, outServer.URL+"/page", http.StatusFound)
+					return
+				}
+
+				header = r.Header.Clone()
+			}))
+			defer server.Close()
+
+			serverURL, err := url.Parse(server.URL)
+			require.NoError(t, err)
+
+			config := test.config
+			config.AuthScope = func(u *url.URL) bool {
+				return u.Host == serverURL.Host
+			}
+
+			l := newLoader(t, config)
+
+			_, err = l.LoadPage(context.Background(), server.URL+"/page")
+			require.NoError(t, err)
+
+			require.Equal(t, test.expAuth, header.Get(HeaderAuthorization))
+			require.Equal(t, test.expCookie, header.Get(HeaderCookie))
+
+			// credentials are not sent to out of scope hosts after a redirect
+			_, err = l.LoadPage(context.Background(), server.URL+"/out")
+			require.NoError(t, err)
+
+			require.NotNil(t, outHeader)
+			require.Empty(t, outHeader.Get(HeaderAuthorization))
+			require.Empty(t, outHeader.Get(HeaderCookie))
+		})
+	}
+}
+
+func TestLoader_CookieFile(t *testing.T) {
+	t.Parallel()
+
+	var cookies, outCookies string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = r.Header.Get(HeaderCookie)
+	}))
+	defer server.Close()
+
+	outServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		outCookies = r.Header.Get(HeaderCookie)
+	}))
+	defer outServer.Close()
+
+	expires := time.Now().Add(time.Hour).Unix()
+
+	cookieFile := filepath.Join(t.TempDir(), "cookies.txt")
+	require.NoError(t, ioutil.WriteFile(cookieFile, []byte(fmt.Sprintf(
+		"# Netscape HTTP Cookie File\n"+
+			"\n"+
+			"127.0.0.1\tFALSE\t/\tFALSE\t0\tsession\tabc\n"+
+			"#HttpOnly_127.0.0.1\tFALSE\t/\tFALSE\t%d\ttoken\txyz\n"+
+			"127.0.0.1\tFALSE\t/\tFALSE\t1\texpired\t1\n"+
+			"127.0.0.1\tFALSE\t/private\tFALSE\t0\tprivate\t1\n",
+		expires)), 0600))
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	l := newLoader(t, Config{
+		CookieFile: cookieFile,
+		AuthScope: func(u *url.URL) bool {
+			return u.Host == serverURL.Host
+		},
+	})
+
+	_, err = l.LoadPage(context.Background(), server.URL+"/page")
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"session=abc", "token=xyz"}, strings.Split(cookies, "; "))
+
+	_, err = l.LoadPage(context.Background(), outServer.URL+"/page")
+	require.NoError(t, err)
+	require.Empty(t, outCookies)
+}
+
+func TestLoader_loadCookies(t *testing.T) {
+	t.Parallel()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	src := ".e.com\tTRUE\t/\tTRUE\t0\tdomain\t1\n" +
+		"e.com\tFALSE\t/\tFALSE\t0\thost\t2\n"
+
+	require.NoError(t, loadCookies(jar, strings.NewReader(src)))
+
+	names := func(u string) []string {
+		pageURL, err := url.Parse(u)
+		require.NoError(t, err)
+
+		var res []string
+		for _, c := range jar.Cookies(pageURL) {
+			res = append(res, c.Name)
+		}
+
+		return res
+	}
+
+	require.ElementsMatch(t, []string{"domain", "host"}, names("https://e.com/"))
+	require.ElementsMatch(t, []string{"domain"}, names("https://www.e.com/"))
+	require.ElementsMatch(t, []string{"host"}, names("http://e.com/"))
+
+	require.Error(t, loadCookies(jar, strings.NewReader("e.com\tFALSE\t/\n")))
+	require.Error(t, loadCookies(jar, strings.NewReader("e.com\tFALSE\t/\tFALSE\tnever\tname\tvalue\n")))
+}
+
+func TestLoader_Login(t *testing.T) {
+	t.Parallel()
+
+	const loginPage = `<html><body>
+	<form action="/search" method="get"><input name="q"></form>
+	<form action="/session" method="post">
+		<input type="hidden" name="csrf" value="token123">
+		<input type="text" name="user">
+		<input type="password" name="password">
+		<input type="submit" name="go" value="Sign in">
+	</form>
+	</body></html>`
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pageOK) //nolint:errcheck
+	})
+
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "pre", Value: "1"})
+		_, _ = w.Write([]byte(loginPage)) //nolint:errcheck
+	})
+
+	mux.HandleFunc("/session", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("pre"); err != nil || r.Method != http.MethodPost ||
+			r.PostFormValue("csrf") != "token123" ||
+			r.PostFormValue("user") != "admin" ||
+			r.PostFormValue("password") != "secret" ||
+			len(r.PostFormValue("go")) > 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "sid", Value: "42"})
+		http.Redirect(w, r, "/", http.StatusFound)
+	})
+
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("sid"); err != nil || c.Value != "42" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		_, _ = w.Write(pageOK) //nolint:errcheck
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	login := func(password string) *Loader {
+		return newLoader(t, Config{
+			Login: &FormLogin{
+				URL:    server.URL + "/login",
+				Fields: url.Values{"user": {"admin"}, "password": {password}},
+			},
+		})
+	}
+
+	l := login("secret")
+	require.NoError(t, l.Login(ctx))
+
+	res, err := l.LoadPage(ctx, server.URL+"/private")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	require.Error(t, login("wrong").Login(ctx))
+
+	// login isn't configured
+	require.NoError(t, newLoader(t, Config{}).Login(ctx))
+}
+
+func TestLoader_getLoginForm(t *testing.T) {
+	t.Parallel()
+
+	action, method, fields := getLoginForm(parsePage([]byte(`<form action="find" method="GET"><input name="q" value="x"></form>`)), "http://e.com/a/login")
+	require.Equal(t, "http://e.com/a/find", action)
+	require.Equal(t, http.MethodGet, method)
+	require.Equal(t, url.Values{"q": {"x"}}, fields)
+
+	action, method, fields = getLoginForm(parsePage([]byte(`<p>no form</p>`)), "http://e.com/login")
+	require.Equal(t, "http://e.com/login", action)
+	require.Equal(t, http.MethodPost, method)
+	require.Empty(t, fields)
+}
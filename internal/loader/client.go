@@ -0,0 +1,187 @@
+package loader
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
+)
+
+const (
+	// DefaultTimeout is a timeout of a whole request including reading the body if Config.Timeout isn't set.
+	DefaultTimeout = 30 * time.Second
+
+	// DefaultConnectTimeout is a timeout of establishing a connection if Config.ConnectTimeout isn't set.
+	DefaultConnectTimeout = 10 * time.Second
+
+	HeaderUserAgent = "User-Agent"
+)
+
+type (
+	// limitedTransport waits for the rate limiter before every request including retries and redirects.
+	// A request holds its limiter slot until the response body is closed.
+	limitedTransport struct {
+		next    http.RoundTripper
+		limiter *ratelimit.Limiter
+	}
+
+	// releaseBody is a response body that releases a limiter slot when it's closed
+	releaseBody struct {
+		io.ReadCloser
+		release func()
+	}
+)
+
+// proxySchemes are supported proxy URL schemes
+var proxySchemes = map[string]interface{}{
+	"http":    nil,
+	"https":   nil,
+	"socks5":  nil,
+	"socks5h": nil,
+}
+
+// newClient creates an HTTP client with timeouts, proxy and TLS settings of the config.
+// If a proxy isn't set, HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.
+func newClient(config Config) (*http.Client, error) {
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unexpected default transport type %T", http.DefaultTransport)
+	}
+
+	transport = transport.Clone()
+
+	transport.DialContext = (&net.Dialer{
+		Timeout:   config.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+
+	if len(config.Proxy) > 0 {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("bad proxy URL [%v]: %w", config.Proxy, err)
+		}
+
+		if _, ok := proxySchemes[proxyURL.Scheme]; !ok || len(proxyURL.Host) == 0 {
+			return nil, fmt.Errorf("bad proxy URL [%v]: http, https, socks5 or socks5h URL is expected", config.Proxy)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if len(config.CAFiles) > 0 || config.InsecureSkipVerify {
+
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: config.InsecureSkipVerify, //nolint:gosec
+			MinVersion:         tls.VersionTLS12,
+		}
+
+		if len(config.CAFiles) > 0 {
+			pool, err := loadCAs(config.CAFiles)
+			if err != nil {
+				return nil, err
+			}
+
+			tlsConfig.RootCAs = pool
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	client := http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+	}
+
+	if config.Limiter != nil {
+		client.Transport = &limitedTransport{
+			next:    transport,
+			limiter: config.Limiter,
+		}
+	}
+
+	if len(config.CookieFile) > 0 || config.Login != nil {
+		jar, err := newCookieJar(config.CookieFile, config.AuthScope)
+		if err != nil {
+			return nil, err
+		}
+
+		client.Jar = jar
+	}
+
+	return &client, nil
+}
+
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	release, err := t.limiter.Wait(req.Context(), req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	resp.Body = &releaseBody{
+		ReadCloser: resp.Body,
+		release:    release,
+	}
+
+	return resp, nil
+}
+
+func (b *releaseBody) Close() error {
+	defer b.release()
+
+	return b.ReadCloser.Close()
+}
+
+// loadCAs returns a system certificate pool with certificates from PEM files added.
+func loadCAs(fileNames []string) (*x509.CertPool, error) {
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	for _, fileName := range fileNames {
+
+		buf, err := ioutil.ReadFile(fileName) //nolint:gosec
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+
+		if !pool.AppendCertsFromPEM(buf) {
+			return nil, fmt.Errorf("CA file [%v] has no PEM certificates", fileName)
+		}
+	}
+
+	return pool, nil
+}
+
+// setRequestHeaders sets User-Agent and extra headers of the config to the request.
+func (l *Loader) setRequestHeaders(req *http.Request) {
+
+	if len(l.config.UserAgent) > 0 {
+		req.Header.Set(HeaderUserAgent, l.config.UserAgent)
+	}
+
+	for name, values := range l.config.Headers {
+		req.Header.Del(name)
+
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+}
@@ -0,0 +1,186 @@
+package loader
+
+import (
+	"context"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
+)
+
+func TestLoader_RequestHeaders(t *testing.T) {
+	t.Parallel()
+
+	var header http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	l := newLoader(t, Config{
+		UserAgent: "sitemap-generator/1.0",
+		Headers: http.Header{
+			"Accept-Language": {"en"},
+			"X-Test":          {"1", "2"},
+		},
+		Cookies: []*http.Cookie{
+			{Name: "session", Value: "abc"},
+			{Name: "lang", Value: "en"},
+		},
+	})
+
+	_, err := l.LoadPage(context.Background(), server.URL)
+	require.NoError(t, err)
+
+	require.Equal(t, "sitemap-generator/1.0", header.Get(HeaderUserAgent))
+	require.Equal(t, "en", header.Get("Accept-Language"))
+	require.Equal(t, []string{"1", "2"}, header.Values("X-Test"))
+	require.Equal(t, "session=abc; lang=en", header.Get("Cookie"))
+}
+
+func TestLoader_TLS(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(pageOK) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, ioutil.WriteFile(caFile, caPEM, 0600))
+
+	type Test struct {
+		config Config
+		expErr bool
+	}
+
+	tests := map[string]Test{
+		"unknown CA":           {config: Config{}, expErr: true},
+		"CA file":              {config: Config{CAFiles: []string{caFile}}, expErr: false},
+		"insecure skip verify": {config: Config{InsecureSkipVerify: true}, expErr: false},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newLoader(t, test.config).LoadPage(context.Background(), server.URL)
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLoader_Proxy(t *testing.T) {
+	t.Parallel()
+
+	var requestedURL string
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedURL = r.URL.String()
+		_, _ = w.Write(pageOK) //nolint:errcheck
+	}))
+	defer proxy.Close()
+
+	res, err := newLoader(t, Config{Proxy: proxy.URL}).LoadPage(context.Background(), "http://e.invalid/page")
+	require.NoError(t, err)
+
+	require.Equal(t, "http://e.invalid/page", requestedURL)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestLoader_Timeout(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+
+	_, err := newLoader(t, Config{Timeout: 50 * time.Millisecond}).LoadPage(context.Background(), server.URL)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+}
+
+func TestLoader_Limiter(t *testing.T) {
+	t.Parallel()
+
+	var nRequests int32
+
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusFound))
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&nRequests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		_, _ = w.Write(pageOK) //nolint:errcheck
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	l := newLoader(t, Config{
+		MaxRetries:     1,
+		RetryBaseDelay: time.Millisecond,
+		Limiter:        ratelimit.New(ratelimit.Config{RPS: 20, MaxInFlight: 1}),
+	})
+
+	start := time.Now()
+
+	res, err := l.LoadPage(context.Background(), server.URL+"/a")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	// a redirect hop and a retry are limited as well, so 4 requests take at least 3 intervals
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestNew_BadConfig(t *testing.T) {
+	t.Parallel()
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, ioutil.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	tests := map[string]Config{
+		"proxy scheme":   {Proxy: "ftp://proxy.e.com"},
+		"proxy host":     {Proxy: "proxy.e.com:8080"},
+		"no CA file":     {CAFiles: []string{filepath.Join(t.TempDir(), "missing.pem")}},
+		"CA file no PEM": {CAFiles: []string{notPEM}},
+	}
+
+	//nolint:paralleltest
+	for description, config := range tests {
+		config := config
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(config)
+			require.Error(t, err)
+		})
+	}
+}
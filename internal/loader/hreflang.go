@@ -0,0 +1,49 @@
+package loader
+
+import (
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"golang.org/x/net/html"
+)
+
+const (
+	AttrHreflang = "hreflang"
+
+	RelAlternate = "alternate"
+)
+
+// getAlternates returns localized versions of the page from <link rel="alternate" hreflang="..." href="...">.
+// Languages are lowercased, URLs are resolved against the base URL. Links with invalid URLs are ignored.
+func getAlternates(node *html.Node, baseURL, pageURL string) []core.Alternate {
+
+	if node == nil {
+		return nil
+	}
+
+	var alternates []core.Alternate
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode && n.Data == TagLink && hasToken(getAttr(n, AttrRel), RelAlternate) {
+
+			lang := strings.ToLower(strings.TrimSpace(getAttr(n, AttrHreflang)))
+
+			if urls := resolveURLs([]string{getAttr(n, AttrHref)}, baseURL, pageURL); len(lang) > 0 && len(urls) > 0 {
+				alternates = append(alternates, core.Alternate{
+					Lang: lang,
+					URL:  urls[0],
+				})
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	return alternates
+}
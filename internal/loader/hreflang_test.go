@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_getAlternates(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		page          string
+		expAlternates []core.Alternate
+	}
+
+	tests := map[string]Test{
+		"OK": {
+			page: `<html><head>
+<link rel="alternate" hreflang="en" href="/en/page">
+<link rel="alternate" hreflang="de-AT" href="http://e.de/page">
+<link rel="alternate" hreflang="x-default" href="/page">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+<link rel="canonical" hreflang="fr" href="/fr/page">
+</head></html>`,
+			expAlternates: []core.Alternate{
+				{Lang: "en", URL: "http://e.com/en/page"},
+				{Lang: "de-at", URL: "http://e.de/page"},
+				{Lang: "x-default", URL: "http://e.com/page"},
+			},
+		},
+
+		"no alternates": {
+			page:          `<html><head><link rel="alternate" hreflang="en" href="mailto:a@e.com"></head></html>`,
+			expAlternates: nil,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			alternates := getAlternates(parsePage([]byte(test.page)), "http://e.com/dir/page", "http://e.com/dir/page")
+
+			require.Equal(t, test.expAlternates, alternates)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package loader
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	TagImg     = "img"
+	TagPicture = "picture"
+	TagSource  = "source"
+
+	AttrSrcset = "srcset"
+
+	PropertyOGImage          = "og:image"
+	PropertyOGImageURL       = "og:image:url"
+	PropertyOGImageSecureURL = "og:image:secure_url"
+)
+
+// getImages returns absolute URLs of page images without duplicates:
+// <img> src and srcset, <picture> <source> srcset and og:image.
+// Relative URLs are resolved against the base URL.
+func getImages(node *html.Node, baseURL, pageURL string) []string {
+
+	if node == nil {
+		return nil
+	}
+
+	var images []string
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode {
+
+			switch n.Data {
+
+			case TagImg:
+				images = append(images, getAttr(n, AttrSrc))
+				images = append(images, parseSrcset(getAttr(n, AttrSrcset))...)
+
+			case TagSource:
+				if n.Parent != nil && n.Parent.Type == html.ElementNode && n.Parent.Data == TagPicture {
+					images = append(images, parseSrcset(getAttr(n, AttrSrcset))...)
+				}
+
+			case TagMeta:
+				switch getAttr(n, AttrProperty) {
+				case PropertyOGImage, PropertyOGImageURL, PropertyOGImageSecureURL:
+					images = append(images, getAttr(n, AttrContent))
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	return resolveURLs(images, baseURL, pageURL)
+}
+
+// parseSrcset returns URLs of srcset candidates like "img-1x.png 1x, img-2x.png 2x".
+func parseSrcset(srcset string) []string {
+
+	var res []string
+
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			res = append(res, fields[0])
+		}
+	}
+
+	return res
+}
+
+// resolveURLs resolves URLs against the base URL (that is resolved against the page URL) and removes duplicates.
+// Empty, data: and other non-HTTP URLs are dropped.
+func resolveURLs(urls []string, baseURL, pageURL string) []string {
+
+	urlPage, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	urlBase, err := urlPage.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	var res []string
+	seen := make(map[string]interface{}, len(urls))
+
+	for _, u := range urls {
+
+		u = strings.TrimSpace(u)
+		if len(u) == 0 {
+			continue
+		}
+
+		resURL, err := urlBase.Parse(u)
+		if err != nil || (resURL.Scheme != "http" && resURL.Scheme != "https") {
+			continue
+		}
+
+		resURL.Fragment = ""
+		s := resURL.String()
+
+		if _, ok := seen[s]; ok {
+			continue
+		}
+
+		seen[s] = nil
+		res = append(res, s)
+	}
+
+	return res
+}
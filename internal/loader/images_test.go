@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_getImages(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		page      string
+		baseURL   string
+		expImages []string
+	}
+
+	tests := map[string]Test{
+		"all sources": {
+			page: `<html><head>
+<meta property="og:image" content="https://cdn.e.com/og.png">
+</head><body>
+<img src="/logo.png" srcset="/logo-1x.png 1x, /logo-2x.png 2x">
+<picture>
+	<source srcset="/hero.webp 1024w, /hero-small.webp 512w" type="image/webp">
+	<img src="images/hero.jpg">
+</picture>
+<video><source src="/video.mp4"></video>
+</body></html>`,
+			baseURL: "http://e.com/dir/page",
+			expImages: []string{
+				"https://cdn.e.com/og.png",
+				"http://e.com/logo.png",
+				"http://e.com/logo-1x.png",
+				"http://e.com/logo-2x.png",
+				"http://e.com/hero.webp",
+				"http://e.com/hero-small.webp",
+				"http://e.com/dir/images/hero.jpg",
+			},
+		},
+
+		"base and duplicates": {
+			page:      `<html><body><img src="a.png"><img src="a.png#x"><img src="http://e.com/img/a.png"></body></html>`,
+			baseURL:   "/img/",
+			expImages: []string{"http://e.com/img/a.png"},
+		},
+
+		"non-HTTP images": {
+			page:      `<html><body><img src="data:image/png;base64,iVBORw0KGgo="><img src=""><img></body></html>`,
+			baseURL:   "http://e.com",
+			expImages: nil,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			images := getImages(parsePage([]byte(test.page)), test.baseURL, "http://e.com/dir/page")
+
+			require.Equal(t, test.expImages, images)
+		})
+	}
+}
+
+func TestLoader_parseSrcset(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"a.png", "b.png", "c.png"}, parseSrcset(" a.png 1x,b.png 2x , c.png"))
+	require.Nil(t, parseSrcset(""))
+}
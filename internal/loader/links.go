@@ -0,0 +1,158 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"golang.org/x/net/html"
+)
+
+const (
+	TagArea   = "area"
+	TagIframe = "iframe"
+	TagFrame  = "frame"
+
+	AttrSrc       = "src"
+	AttrDataHref  = "data-href"
+	AttrHTTPEquiv = "http-equiv"
+
+	// AnyTag is a LinkSource tag that matches all tags
+	AnyTag = "*"
+
+	HTTPEquivRefresh = "refresh"
+)
+
+type (
+	// LinkSource is a tag attribute that links are extracted from.
+	// If Rels is set a tag should have any of rel values.
+	// <meta http-equiv="refresh" content="..."> is a special case: a link is a url= part of the content.
+	LinkSource struct {
+		Tag  string
+		Attr string
+		Rels []string
+	}
+)
+
+// LinkSources are link sources by names that are used in Config.LinkSources.
+var LinkSources = map[string]LinkSource{
+	"a":            {Tag: TagA, Attr: AttrHref},
+	"area":         {Tag: TagArea, Attr: AttrHref},
+	"link":         {Tag: TagLink, Attr: AttrHref, Rels: []string{"alternate", "next", "prev"}},
+	"iframe":       {Tag: TagIframe, Attr: AttrSrc},
+	"frame":        {Tag: TagFrame, Attr: AttrSrc},
+	"meta-refresh": {Tag: TagMeta, Attr: AttrContent},
+	"data-href":    {Tag: AnyTag, Attr: AttrDataHref},
+}
+
+// DefaultLinkSources are names of link sources that are used if Config.LinkSources isn't set.
+var DefaultLinkSources = []string{"a"}
+
+// AllLinkSources are names of all link sources.
+var AllLinkSources = []string{"a", "area", "link", "iframe", "frame", "meta-refresh", "data-href"}
+
+// linkHTMLTypes are <link> type values of HTML pages. <link> elements of other types (like RSS feeds) are ignored.
+var linkHTMLTypes = map[string]interface{}{
+	"":                      nil,
+	"text/html":             nil,
+	"application/xhtml+xml": nil,
+}
+
+// getLinkSources returns link sources by names.
+func getLinkSources(names []string) ([]LinkSource, error) {
+
+	res := make([]LinkSource, 0, len(names))
+
+	for _, name := range names {
+		source, ok := LinkSources[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("unknown link source [%v]", name)
+		}
+
+		res = append(res, source)
+	}
+
+	return res, nil
+}
+
+// getNodeLinks returns links of the element node from all matching link sources.
+// Links with rel="nofollow" are marked.
+func getNodeLinks(n *html.Node, sources []LinkSource) []core.Link {
+
+	var links []core.Link
+
+	for _, source := range sources {
+
+		if source.Tag != AnyTag && source.Tag != n.Data {
+			continue
+		}
+
+		rel := getAttr(n, AttrRel)
+
+		if len(source.Rels) > 0 && !hasAnyToken(rel, source.Rels) {
+			continue
+		}
+
+		if n.Data == TagLink {
+			if _, ok := linkHTMLTypes[strings.ToLower(strings.TrimSpace(getAttr(n, AttrType)))]; !ok {
+				continue
+			}
+		}
+
+		value := getAttr(n, source.Attr)
+
+		if source.Tag == TagMeta {
+			if !strings.EqualFold(getAttr(n, AttrHTTPEquiv), HTTPEquivRefresh) {
+				continue
+			}
+
+			value = getRefreshURL(value)
+		}
+
+		if value = strings.TrimSpace(value); len(value) == 0 {
+			continue
+		}
+
+		links = append(links, core.Link{
+			URL:      value,
+			NoFollow: hasToken(rel, RelNofollow),
+			Tag:      n.Data,
+			Attr:     source.Attr,
+		})
+	}
+
+	return links
+}
+
+// getRefreshURL returns a URL of <meta http-equiv="refresh"> content like "5; url=/page".
+// It returns an empty string if the content has no URL.
+func getRefreshURL(content string) string {
+
+	_, rest, ok := strings.Cut(content, ";")
+	if !ok {
+		_, rest, ok = strings.Cut(content, ",")
+		if !ok {
+			return ""
+		}
+	}
+
+	rest = strings.TrimSpace(rest)
+
+	key, value, ok := strings.Cut(rest, "=")
+	if !ok || !strings.EqualFold(strings.TrimSpace(key), "url") {
+		return ""
+	}
+
+	return strings.Trim(strings.TrimSpace(value), `'"`)
+}
+
+// hasAnyToken checks if a space-separated list of tokens has any of tokens.
+func hasAnyToken(list string, tokens []string) bool {
+	for _, token := range tokens {
+		if hasToken(list, token) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,127 @@
+package loader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_getLinksAndBaseSources(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		sources  []string
+		page     string
+		expLinks []core.Link
+	}
+
+	page := `<html><head>
+<meta http-equiv="refresh" content="5; url='/refresh'">
+<meta name="description" content="5; url=/not-refresh">
+<link rel="alternate" hreflang="de" href="/de">
+<link rel="next" href="/page2">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+<link rel="alternate" type="text/html" hreflang="fr" href="/fr">
+<link rel="stylesheet" href="/style.css">
+</head><body>
+<a href="/anchor" rel="nofollow">Anchor</a>
+<map><area href="/area"></map>
+<iframe src="/iframe"></iframe>
+<frameset><frame src="/frame"></frameset>
+<div data-href="/div"></div>
+</body></html>`
+
+	tests := map[string]Test{
+		"all sources": {
+			sources: AllLinkSources,
+			page:    page,
+			expLinks: []core.Link{
+				{URL: "/refresh", Tag: TagMeta, Attr: AttrContent},
+				{URL: "/de", Tag: TagLink, Attr: AttrHref},
+				{URL: "/page2", Tag: TagLink, Attr: AttrHref},
+				{URL: "/fr", Tag: TagLink, Attr: AttrHref},
+				{URL: "/anchor", NoFollow: true, Tag: TagA, Attr: AttrHref},
+				{URL: "/area", Tag: TagArea, Attr: AttrHref},
+				{URL: "/iframe", Tag: TagIframe, Attr: AttrSrc},
+				{URL: "/div", Tag: "div", Attr: AttrDataHref},
+			},
+		},
+
+		"selected sources": {
+			sources: []string{"a", "data-href"},
+			page:    page,
+			expLinks: []core.Link{
+				{URL: "/anchor", NoFollow: true, Tag: TagA, Attr: AttrHref},
+				{URL: "/div", Tag: "div", Attr: AttrDataHref},
+			},
+		},
+
+		"frames": {
+			sources: []string{"frame"},
+			page:    `<html><frameset><frame src="/frame1"><frame src="/frame2"></frameset></html>`,
+			expLinks: []core.Link{
+				{URL: "/frame1", Tag: TagFrame, Attr: AttrSrc},
+				{URL: "/frame2", Tag: TagFrame, Attr: AttrSrc},
+			},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			sources, err := getLinkSources(test.sources)
+			require.NoError(t, err)
+
+			links, _ := getLinksAndBase(parsePage([]byte(test.page)), sources)
+
+			require.Equal(t, test.expLinks, links)
+		})
+	}
+}
+
+func TestLoader_getLinkSources(t *testing.T) {
+	t.Parallel()
+
+	sources, err := getLinkSources(DefaultLinkSources)
+	require.NoError(t, err)
+	require.Equal(t, []LinkSource{LinkSources["a"]}, sources)
+
+	sources, err = getLinkSources([]string{"A", " iframe "})
+	require.NoError(t, err)
+	require.Equal(t, []LinkSource{LinkSources["a"], LinkSources["iframe"]}, sources)
+
+	_, err = getLinkSources([]string{"a", "img"})
+	require.Error(t, err)
+
+	_, err = New(Config{LinkSources: []string{"img"}})
+	require.Error(t, err)
+}
+
+func TestLoader_getRefreshURL(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"5; url=/page":          "/page",
+		"0;URL='/quoted'":       "/quoted",
+		`0; url="/dquoted"`:     "/dquoted",
+		"0, url=http://e.com/a": "http://e.com/a",
+		"5":                     "",
+		"5; /page":              "",
+	}
+
+	//nolint:paralleltest
+	for content, expURL := range tests {
+		content, expURL := content, expURL
+
+		t.Run(content, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, expURL, getRefreshURL(content))
+		})
+	}
+}
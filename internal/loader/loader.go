@@ -3,117 +3,454 @@ package loader
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
 	"golang.org/x/net/html"
+	"io"
 	"io/ioutil"
 	"log"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 const (
 	TagA     = "a"
 	TagBase  = "base"
 	AttrHref = "href"
+	AttrRel  = "rel"
+
+	RelNofollow = "nofollow"
+
+	HeaderContentType = "Content-Type"
+
+	// DefaultMaxBodySize is a max size of a page body that is read if Config.MaxBodySize isn't set.
+	DefaultMaxBodySize = 10 * 1024 * 1024
+
+	// MaxRobotsSize is a max size of robots.txt that is read. The rest of the file is ignored.
+	MaxRobotsSize = 500 * 1024
+
+	// MaxSitemapSize is a max size of a sitemap file according to the protocol.
+	MaxSitemapSize = 50 * 1024 * 1024
+
+	// DefaultMaxRedirects is a max number of redirects that are followed if Config.MaxRedirects isn't set.
+	DefaultMaxRedirects = 10
+)
+
+// errTooManyRedirects is returned when a redirect chain is longer than Config.MaxRedirects. It's not retried.
+var errTooManyRedirects = errors.New("too many redirects")
+
+// htmlContentTypes are content types of pages that are parsed for links.
+var htmlContentTypes = map[string]interface{}{
+	"text/html":             nil,
+	"application/xhtml+xml": nil,
+}
+
+type (
+	Loader struct {
+		config      Config
+		client      *http.Client
+		linkSources []LinkSource
+
+		// retries is a number of retries taken from Config.RetryBudget
+		retries int64
+	}
+
+	Config struct {
+		// MaxRetries is a max number of retries of a single URL. Zero disables retries.
+		MaxRetries int
+
+		// RetryBudget is a max number of retries of all URLs. Zero means no limit.
+		RetryBudget int
+
+		// RetryBaseDelay is a delay before the first retry. Every next retry doubles it up to RetryMaxDelay.
+		// RetryMaxDelay limits Retry-After delay as well. Zero RetryMaxDelay means no limit.
+		RetryBaseDelay time.Duration
+		RetryMaxDelay  time.Duration
+
+		// MaxBodySize is a max size of a page body that is read. A longer body is truncated.
+		// Zero value means DefaultMaxBodySize.
+		MaxBodySize int64
+
+		// RedirectScope checks every redirect hop of a page. If it's set and a hop is out of scope,
+		// the redirect is not followed and LoadPage returns core.ErrOutOfScope error.
+		// Robots.txt, sitemaps and login redirects are not checked.
+		RedirectScope func(*url.URL) bool
+
+		// MaxRedirects is a max number of redirects that are followed. Nil value means DefaultMaxRedirects,
+		// zero disables following redirects, so a redirect response is returned as it is.
+		MaxRedirects *int
+
+		// LinkSources are names of LinkSources that links are extracted from. Empty value means DefaultLinkSources.
+		LinkSources []string
+
+		// Limiter limits every request including retries, redirects, robots.txt and sitemaps.
+		// If it's nil requests are not limited.
+		Limiter *ratelimit.Limiter
+
+		// Renderer renders HTML pages before parsing, so links added by client-side scripts are found.
+		// Pages are fetched statically first anyway to get a status, headers and redirects.
+		// If it's nil static page bodies are parsed.
+		Renderer Renderer
+
+		// Timeout limits a whole request including reading the body, ConnectTimeout limits establishing a connection.
+		// Zero values mean DefaultTimeout and DefaultConnectTimeout.
+		Timeout        time.Duration
+		ConnectTimeout time.Duration
+
+		// UserAgent is sent in User-Agent header. Go default one is sent if it's empty.
+		// Headers are added to every request, they override User-Agent.
+		UserAgent string
+		Headers   http.Header
+
+		// Cookies are added to every request to AuthScope hosts.
+		Cookies []*http.Cookie
+
+		// BasicUser and BasicPassword enable HTTP basic auth. BearerToken enables bearer token auth instead.
+		BasicUser     string
+		BasicPassword string
+		BearerToken   string
+
+		// CookieFile is a Netscape cookies file (exported from a browser or saved by curl) that fills a cookie jar.
+		// Login is a form login that fills a cookie jar when Login() is called.
+		// Cookies that are set by a site are kept in the jar if any of them is set.
+		CookieFile string
+		Login      *FormLogin
+
+		// AuthScope checks if credentials (auth headers, Cookies and cookie jar cookies) may be sent to a URL.
+		// If it's nil credentials are sent to all URLs.
+		AuthScope func(*url.URL) bool
+
+		// Proxy is an http, https, socks5 or socks5h proxy URL. If it's empty proxy environment variables are used.
+		Proxy string
+
+		// CAFiles are PEM files with CA certificates that are trusted along with system ones.
+		// InsecureSkipVerify disables TLS certificates verification.
+		CAFiles            []string
+		InsecureSkipVerify bool
+	}
 )
 
-type Loader struct{}
+// New creates a loader. It returns an error if the config has unknown link sources, bad proxy URL or CA files.
+func New(config Config) (*Loader, error) {
+
+	config = setDefaults(config)
+
+	linkSources, err := getLinkSources(config.LinkSources)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Loader{
+		config:      config,
+		client:      client,
+		linkSources: linkSources,
+	}, nil
+}
+
+// setDefaults returns the config with default values of unset fields.
+func setDefaults(config Config) Config {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+
+	if config.MaxRedirects == nil || *config.MaxRedirects < 0 {
+		maxRedirects := DefaultMaxRedirects
+		config.MaxRedirects = &maxRedirects
+	}
+
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.ConnectTimeout <= 0 {
+		config.ConnectTimeout = DefaultConnectTimeout
+	}
+
+	if len(config.LinkSources) == 0 {
+		config.LinkSources = DefaultLinkSources
+	}
 
-func New() *Loader {
-	return &Loader{}
+	return config
 }
 
-// GetPageLinks returns all URLs of <a> tags on the page. URLs are absolute.
-// GetPageLinks ignores invalid URLs including <base> href URL.
-func (l *Loader) GetPageLinks(ctx context.Context, pageURL string) ([]string, error) {
-	page, err := getPage(ctx, pageURL)
+// LoadPage returns all URLs of link sources on the page, page metadata, robots directives and canonical URL.
+// URLs are absolute. If the page redirects, LoadPage returns the final page, its URL and the redirect chain,
+// and relative URLs are resolved against the final URL.
+// Only successful (2xx) responses with text/html or application/xhtml+xml content are parsed
+// (rendered by Config.Renderer first if it's set), other pages have a status code, a content type and metadata from headers only.
+// LoadPage ignores invalid URLs including <base> href URL.
+func (l *Loader) LoadPage(ctx context.Context, pageURL string) (*core.Page, error) {
+	resp, err := l.getPage(ctx, pageURL, l.config.MaxBodySize, l.config.RedirectScope)
 	if err != nil {
 		// log.Printf("failed to load page: %v", err)
 		return nil, fmt.Errorf("failed to load page: %w", err)
 	}
 
-	links, bases := getLinksAndBase(page)
+	contentType := getContentType(resp)
+
+	page := core.Page{
+		StatusCode:  resp.statusCode,
+		ContentType: contentType,
+		FinalURL:    resp.finalURL,
+		Redirects:   resp.redirects,
+	}
+
+	if len(resp.finalURL) > 0 {
+		pageURL = resp.finalURL
+	}
+
+	if resp.statusCode < 200 || resp.statusCode >= 300 {
+		return &page, nil
+	}
+
+	if _, ok := htmlContentTypes[contentType]; !ok {
+		page.Meta = getPageMeta(resp.header, nil)
+		page.NoIndex, page.NoFollow = getRobotsDirectives(resp.header, nil)
+		page.Canonical = getCanonical(resp.header, nil, pageURL, pageURL)
+
+		return &page, nil
+	}
+
+	body, truncated := l.render(ctx, pageURL, resp)
+
+	if truncated {
+		log.Printf("ERR: page [%v] is larger than %v bytes, the rest of it is ignored", pageURL, l.config.MaxBodySize)
+	}
+
+	node := parsePage(body)
+
+	links, bases := getLinksAndBase(node, l.linkSources)
+
+	baseURL := getBaseURL(bases, pageURL)
+
+	page.Links = updateLinksWithBase(links, baseURL, pageURL)
+	page.Meta = getPageMeta(resp.header, node)
+	page.Meta.Images = getImages(node, baseURL, pageURL)
+	page.Meta.Videos = getVideos(node, baseURL, pageURL)
+	page.Meta.Article = getArticle(node)
+	page.Meta.Alternates = getAlternates(node, baseURL, pageURL)
+	page.NoIndex, page.NoFollow = getRobotsDirectives(resp.header, node)
+	page.Canonical = getCanonical(resp.header, node, baseURL, pageURL)
+
+	return &page, nil
+}
+
+// getContentType returns a lowercased media type of the response without parameters.
+// If a server doesn't send Content-Type it's detected from the body.
+func getContentType(resp *response) string {
+
+	contentType := resp.header.Get(HeaderContentType)
+	if len(contentType) == 0 {
+		contentType = http.DetectContentType(resp.body)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
 	if err != nil {
-		// log.Printf("failed to extract links from the page: %v", err)
-		return nil, fmt.Errorf("failed to extract links from the page: %w", err)
+		return ""
 	}
 
-	var baseURL string
+	return mediaType
+}
+
+// getBaseURL returns a URL that relative links of the page are resolved against.
+// It's the first <base> href or the page URL if there's no <base>.
+func getBaseURL(bases []string, pageURL string) string {
 
 	if len(bases) == 0 {
-		baseURL = pageURL
-	} else {
-		baseURL = bases[0]
+		return pageURL
+	}
 
-		if len(bases) > 1 {
-			baseValue := fmt.Sprintf(`<base href="%v"/>`, bases[0])
-			log.Printf("ERR: page [%v] has more than one <base>. Applying %v", pageURL, baseValue)
-		}
+	if len(bases) > 1 {
+		baseValue := fmt.Sprintf(`<base href="%v"/>`, bases[0])
+		log.Printf("ERR: page [%v] has more than one <base>. Applying %v", pageURL, baseValue)
 	}
 
-	absLinks := updateLinksWithBase(links, baseURL, pageURL)
+	return bases[0]
+}
+
+// ResolveURL returns a final URL of a page following its redirects. If the page doesn't redirect ResolveURL returns pageURL.
+// Config.RedirectScope isn't checked, so the page may redirect to any host.
+func (l *Loader) ResolveURL(ctx context.Context, pageURL string) (string, error) {
 
-	return absLinks, nil
+	// the body isn't needed
+	resp, err := l.getPage(ctx, pageURL, 0, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to load page: %w", err)
+	}
+
+	if len(resp.finalURL) > 0 {
+		return resp.finalURL, nil
+	}
+
+	return pageURL, nil
 }
 
-func getPage(ctx context.Context, pageURL string) ([]byte, error) {
+// LoadRobots loads and parses robots.txt.
+// If a server responds with 4xx status there are no restrictions and LoadRobots returns empty rules.
+// Other non-2xx statuses are errors, so a caller may treat a host as fully disallowed.
+func (l *Loader) LoadRobots(ctx context.Context, robotsURL string) (*robots.Robots, error) {
+	resp, err := l.getPage(ctx, robotsURL, MaxRobotsSize, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load robots.txt: %w", err)
+	}
+
+	switch {
+	case resp.statusCode >= 200 && resp.statusCode < 300:
+		return robots.Parse(bytes.NewReader(resp.body))
+
+	case resp.statusCode >= 400 && resp.statusCode < 500:
+		return &robots.Robots{}, nil
+
+	default:
+		return nil, fmt.Errorf("robots.txt [%v] responded with status %v", robotsURL, resp.statusCode)
+	}
+}
+
+// response is a loaded HTTP response.
+type response struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+
+	// truncated is true if the body is larger than it's allowed to read
+	truncated bool
+
+	// finalURL is a URL of the response if the request was redirected, otherwise it's empty.
+	// redirects are URLs that responded with a redirect starting with the requested one.
+	finalURL  string
+	redirects []string
+}
+
+// doGet sends a single GET request and reads up to maxBodySize bytes of the response body.
+// Redirects are followed up to Config.MaxRedirects. If redirectScope is set redirects out of it are not followed.
+func (l *Loader) doGet(
+	ctx context.Context,
+	pageURL string,
+	maxBodySize int64,
+	redirectScope func(*url.URL) bool,
+) (*response, error) {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run request: %w", err)
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	return l.do(req, maxBodySize, redirectScope)
+}
+
+// do sends a request with configured headers and credentials and reads up to maxBodySize bytes of the response body.
+// Credentials are checked again on every redirect. If redirectScope is set a redirect out of it is an error.
+func (l *Loader) do(req *http.Request, maxBodySize int64, redirectScope func(*url.URL) bool) (*response, error) {
+
+	l.setRequestHeaders(req)
+	l.setCredentials(req)
+
+	var redirects []string
+
+	client := *l.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		maxRedirects := *l.config.MaxRedirects
+
+		if maxRedirects == 0 {
+			return http.ErrUseLastResponse
+		}
+
+		if len(via) > maxRedirects {
+			return fmt.Errorf("stopped after %d redirects: %w", maxRedirects, errTooManyRedirects)
+		}
+
+		if redirectScope != nil && !redirectScope(req.URL) {
+			return fmt.Errorf("redirect to [%v]: %w", req.URL, core.ErrOutOfScope)
+		}
+
+		redirects = append(redirects, via[len(via)-1].URL.String())
+
+		l.setCredentials(req)
+
+		return nil
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf(" GET request failed: %w", err)
+		return nil, fmt.Errorf(" %v request failed: %w", req.Method, err)
 	}
 
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
 	if err != nil {
 		return nil, fmt.Errorf("failde to read page from response: %w", err)
 	}
 
-	return body, nil
+	truncated := int64(len(body)) > maxBodySize
+	if truncated {
+		body = body[:maxBodySize]
+	}
+
+	res := response{
+		statusCode: resp.StatusCode,
+		header:     resp.Header,
+		body:       body,
+		truncated:  truncated,
+	}
+
+	if len(redirects) > 0 {
+		res.finalURL = resp.Request.URL.String()
+		res.redirects = redirects
+	}
+
+	return &res, nil
 }
 
-// getLinksAndBase extracts all <a> tag links and all <base> href links.
-func getLinksAndBase(page []byte) ([]string, []string) {
+// parsePage parses page HTML. It returns nil if the page can't be parsed.
+func parsePage(page []byte) *html.Node {
 
 	node, err := html.Parse(bytes.NewReader(page))
 	if err != nil {
 		log.Printf("ERR: failed to parse page: %v", err)
+		return nil
+	}
+
+	return node
+}
+
+// getLinksAndBase extracts links of all link sources and all <base> href links.
+// Links with rel="nofollow" are marked.
+func getLinksAndBase(node *html.Node, sources []LinkSource) ([]core.Link, []string) {
+
+	if node == nil {
 		return nil, nil
 	}
 
-	var extractAnchorsFunc func(*html.Node) ([]string, []string)
+	var extractAnchorsFunc func(*html.Node) ([]core.Link, []string)
 
-	extractAnchorsFunc = func(n *html.Node) ([]string, []string) {
+	extractAnchorsFunc = func(n *html.Node) ([]core.Link, []string) {
 
-		var links []string
+		var links []core.Link
 		var bases []string
 
 		if n.Type == html.ElementNode {
 
-			switch n.Data {
-
-			case TagA:
-				for _, attr := range n.Attr {
-					if attr.Key == AttrHref && len(attr.Val) > 0 {
-						links = append(links, attr.Val)
-					}
-				}
-
-			case TagBase:
+			if n.Data == TagBase {
 				for _, attr := range n.Attr {
 					if attr.Key == AttrHref && len(attr.Val) > 0 {
 						bases = append(bases, attr.Val)
 					}
 				}
-
 			}
+
+			links = append(links, getNodeLinks(n, sources)...)
 		}
 
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
@@ -134,9 +471,9 @@ func getLinksAndBase(page []byte) ([]string, []string) {
 // updateLinksWithBase transforms all links to an absolute form.
 // <base> URL is resolved against a page URL (if base URL is relative).
 // Then every link is resolved against absolute base URL.
-func updateLinksWithBase(links []string, base, page string) []string {
+func updateLinksWithBase(links []core.Link, base, page string) []core.Link {
 
-	res := make([]string, 0, len(links))
+	res := make([]core.Link, 0, len(links))
 
 	urlPage, err := url.Parse(page)
 	if err != nil {
@@ -153,25 +490,26 @@ func updateLinksWithBase(links []string, base, page string) []string {
 
 	for _, link := range links {
 
-		noHashLink, _, _ := strings.Cut(link, "#")
+		noHashLink, _, _ := strings.Cut(link.URL, "#")
 
 		if len(noHashLink) == 0 {
 			continue
 		}
 
 		// checking schema to exclude mail links
-		urlLink, err := url.ParseRequestURI(link)
+		urlLink, err := url.ParseRequestURI(link.URL)
 		if err != nil || (urlLink.Scheme != "" && urlLink.Scheme != "http" && urlLink.Scheme != "https") {
 			continue
 		}
 
 		resURL, err := urlBase.Parse(noHashLink)
 		if err != nil {
-			log.Printf("ERR: failed to join base url [%v] and link [%v]: %v", base, link, err)
+			log.Printf("ERR: failed to join base url [%v] and link [%v]: %v", base, link.URL, err)
 			continue
 		}
 
-		res = append(res, resURL.String())
+		link.URL = resURL.String()
+		res = append(res, link)
 	}
 
 	if len(res) == 0 {
@@ -1,12 +1,15 @@
 package loader
 
 import (
+	"bytes"
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
 )
 
 func TestLoader_getLinksAndBase(t *testing.T) {
@@ -14,7 +17,7 @@ func TestLoader_getLinksAndBase(t *testing.T) {
 
 	type Test struct {
 		page     []byte
-		expLinks []string
+		expLinks []core.Link
 		expBases []string
 	}
 
@@ -24,8 +27,11 @@ func TestLoader_getLinksAndBase(t *testing.T) {
 
 	tests := map[string]Test{
 		"OK": {
-			page:     pageOK,
-			expLinks: []string{"http://abs.link.com", "/rel/link"},
+			page: pageOK,
+			expLinks: []core.Link{
+				{URL: "http://abs.link.com", Tag: TagA, Attr: AttrHref},
+				{URL: "/rel/link", Tag: TagA, Attr: AttrHref},
+			},
 			expBases: []string{"http://test.com"},
 		},
 
@@ -35,9 +41,21 @@ func TestLoader_getLinksAndBase(t *testing.T) {
 			expBases: nil,
 		},
 
+		"nofollow links": {
+			page: pageWithRobotsMeta,
+			expLinks: []core.Link{
+				{URL: "/follow", Tag: TagA, Attr: AttrHref},
+				{URL: "/nofollow", NoFollow: true, Tag: TagA, Attr: AttrHref},
+			},
+			expBases: nil,
+		},
+
 		"multiple bases HTML": {
-			page:     badHTMLPage,
-			expLinks: []string{"http://abs.link.com", "/rel/link"},
+			page: badHTMLPage,
+			expLinks: []core.Link{
+				{URL: "http://abs.link.com", Tag: TagA, Attr: AttrHref},
+				{URL: "/rel/link", Tag: TagA, Attr: AttrHref},
+			},
 			expBases: []string{"http://test.com", "http://another.test.com"},
 		},
 	}
@@ -49,7 +67,10 @@ func TestLoader_getLinksAndBase(t *testing.T) {
 		t.Run(description, func(t *testing.T) {
 			t.Parallel()
 
-			links, bases := getLinksAndBase(test.page)
+			sources, err := getLinkSources(DefaultLinkSources)
+			require.NoError(t, err)
+
+			links, bases := getLinksAndBase(parsePage(test.page), sources)
 
 			require.Equal(t, test.expLinks, links)
 			require.Equal(t, test.expBases, bases)
@@ -61,57 +82,57 @@ func TestLoader_updateLinksWithBase(t *testing.T) {
 	t.Parallel()
 
 	type Test struct {
-		links    []string
+		links    []core.Link
 		pageURL  string
 		baseURL  string
-		expLinks []string
+		expLinks []core.Link
 	}
 
 	tests := map[string]Test{
 		"OK": {
-			links: []string{
-				"http://abs.link.com",
-				"/rel/link",
+			links: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "/rel/link", NoFollow: true},
 			},
 			pageURL: "http://hello.com",
 			baseURL: "http://test.com/some/more/",
-			expLinks: []string{
-				"http://abs.link.com",
-				"http://test.com/rel/link",
+			expLinks: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "http://test.com/rel/link", NoFollow: true},
 			},
 		},
 
 		"No base url": {
-			links: []string{
-				"http://abs.link.com",
-				"/rel/link",
+			links: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "/rel/link", NoFollow: true},
 			},
 			pageURL: "http://hello.com",
 			baseURL: "",
-			expLinks: []string{
-				"http://abs.link.com",
-				"http://hello.com/rel/link",
+			expLinks: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "http://hello.com/rel/link", NoFollow: true},
 			},
 		},
 
 		"Relative base URL": {
-			links: []string{
-				"http://abs.link.com",
-				"/rel/link",
+			links: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "/rel/link", NoFollow: true},
 			},
 			pageURL: "http://hello.com/",
 			baseURL: "/some/more",
-			expLinks: []string{
-				"http://abs.link.com",
-				"http://hello.com/rel/link",
+			expLinks: []core.Link{
+				{URL: "http://abs.link.com"},
+				{URL: "http://hello.com/rel/link", NoFollow: true},
 			},
 		},
 
 		"non HTTP links": {
-			links: []string{
-				"mailto:someone@home.com",
-				"someone@home.com",
-				"#page-anchor",
+			links: []core.Link{
+				{URL: "mailto:someone@home.com"},
+				{URL: "someone@home.com"},
+				{URL: "#page-anchor"},
 			},
 			pageURL:  "http://hello.com/",
 			baseURL:  "some/more",
@@ -173,10 +194,242 @@ func TestLoader_getPageLinks(t *testing.T) {
 				_, _ = w.Write(test.srcPage) //nolint:errcheck
 			}))
 
-			ldr := New()
-			res, _ := ldr.GetPageLinks(ctx, server.URL) //nolint:errcheck
+			ldr := newLoader(t, Config{})
+			res, err := ldr.LoadPage(ctx, server.URL)
+			require.NoError(t, err)
 
-			require.Equal(t, test.expRes, res)
+			require.Equal(t, test.expRes, linksToList(res.Links))
 		})
 	}
 }
+
+func TestLoader_LoadRobots(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		status      int
+		body        string
+		expErr      bool
+		expAllowed  bool
+		expSitemaps []string
+	}
+
+	tests := map[string]Test{
+		"OK": {
+			status:      http.StatusOK,
+			body:        "User-agent: *\nDisallow: /admin\nSitemap: http://e.com/sitemap.xml\n",
+			expAllowed:  false,
+			expSitemaps: []string{"http://e.com/sitemap.xml"},
+		},
+
+		"Not found": {
+			status:     http.StatusNotFound,
+			expAllowed: true,
+		},
+
+		"Server error": {
+			status: http.StatusServiceUnavailable,
+			expErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body)) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			ldr := newLoader(t, Config{})
+			res, err := ldr.LoadRobots(ctx, server.URL+"/robots.txt")
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			allowed, _ := res.Group("sitemap-generator").Allowed("/admin")
+			require.Equal(t, test.expAllowed, allowed)
+			require.Equal(t, test.expSitemaps, res.Sitemaps)
+		})
+	}
+}
+
+func TestLoader_LoadPageStatusAndContentType(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		status         int
+		contentType    string
+		body           []byte
+		maxBodySize    int64
+		expContentType string
+		expLinks       []string
+	}
+
+	tests := map[string]Test{
+		"HTML": {
+			status:         http.StatusOK,
+			contentType:    "text/html; charset=utf-8",
+			body:           pageOK,
+			expContentType: "text/html",
+			expLinks:       []string{"http://abs.link.com", "http://test.com/rel/link"},
+		},
+
+		"XHTML": {
+			status:         http.StatusOK,
+			contentType:    "application/xhtml+xml",
+			body:           pageOK,
+			expContentType: "application/xhtml+xml",
+			expLinks:       []string{"http://abs.link.com", "http://test.com/rel/link"},
+		},
+
+		"detected HTML": {
+			status:         http.StatusOK,
+			body:           pageOK,
+			expContentType: "text/html",
+			expLinks:       []string{"http://abs.link.com", "http://test.com/rel/link"},
+		},
+
+		"PDF": {
+			status:         http.StatusOK,
+			contentType:    "application/pdf",
+			body:           pageOK,
+			expContentType: "application/pdf",
+			expLinks:       nil,
+		},
+
+		"not found": {
+			status:         http.StatusNotFound,
+			contentType:    "text/html",
+			body:           pageOK,
+			expContentType: "text/html",
+			expLinks:       nil,
+		},
+
+		"truncated": {
+			status:         http.StatusOK,
+			contentType:    "text/html",
+			body:           pageOK,
+			maxBodySize:    int64(bytes.Index(pageOK, []byte("/rel/link"))),
+			expContentType: "text/html",
+			expLinks:       []string{"http://abs.link.com"},
+		},
+	}
+
+	ctx := context.Background()
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if len(test.contentType) > 0 {
+					w.Header().Set(HeaderContentType, test.contentType)
+				}
+
+				w.WriteHeader(test.status)
+				_, _ = w.Write(test.body) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			res, err := newLoader(t, Config{MaxBodySize: test.maxBodySize}).LoadPage(ctx, server.URL)
+			require.NoError(t, err)
+
+			require.Equal(t, test.status, res.StatusCode)
+			require.Equal(t, test.expContentType, res.ContentType)
+			require.Equal(t, test.expLinks, linksToList(res.Links))
+		})
+	}
+}
+
+func TestLoader_LoadPageRedirects(t *testing.T) {
+	t.Parallel()
+
+	mux := http.NewServeMux()
+	mux.Handle("/a", http.RedirectHandler("/b", http.StatusMovedPermanently))
+	mux.Handle("/b", http.RedirectHandler("/dir/c", http.StatusFound))
+	mux.HandleFunc("/dir/c", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`<html><body><a href="/dir/page">Relative link</a></body></html>`)) //nolint:errcheck
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	res, err := newLoader(t, Config{}).LoadPage(ctx, server.URL+"/a")
+	require.NoError(t, err)
+
+	require.Equal(t, server.URL+"/dir/c", res.FinalURL)
+	require.Equal(t, []string{server.URL + "/a", server.URL + "/b"}, res.Redirects)
+	require.Equal(t, []string{server.URL + "/dir/page"}, linksToList(res.Links))
+
+	res, err = newLoader(t, Config{}).LoadPage(ctx, server.URL+"/dir/c")
+	require.NoError(t, err)
+	require.Empty(t, res.FinalURL)
+	require.Empty(t, res.Redirects)
+
+	maxRedirects := 1
+
+	_, err = newLoader(t, Config{MaxRedirects: &maxRedirects, MaxRetries: 3}).LoadPage(ctx, server.URL+"/a")
+	require.ErrorIs(t, err, errTooManyRedirects)
+
+	inScope := func(u *url.URL) bool { return u.Path != "/dir/c" }
+
+	_, err = newLoader(t, Config{RedirectScope: inScope, MaxRetries: 3}).LoadPage(ctx, server.URL+"/a")
+	require.ErrorIs(t, err, core.ErrOutOfScope)
+
+	finalURL, err := newLoader(t, Config{RedirectScope: inScope}).ResolveURL(ctx, server.URL+"/a")
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/dir/c", finalURL)
+
+	finalURL, err = newLoader(t, Config{}).ResolveURL(ctx, server.URL+"/dir/c")
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/dir/c", finalURL)
+
+	noRedirects := 0
+
+	res, err = newLoader(t, Config{MaxRedirects: &noRedirects}).LoadPage(ctx, server.URL+"/a")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+	require.Empty(t, res.FinalURL)
+	require.Empty(t, res.Redirects)
+}
+
+// newLoader creates a loader failing the test on errors.
+func newLoader(t *testing.T, config Config) *Loader {
+	t.Helper()
+
+	l, err := New(config)
+	require.NoError(t, err)
+
+	return l
+}
+
+func linksToList(links []core.Link) []string {
+
+	if links == nil {
+		return nil
+	}
+
+	res := make([]string, 0, len(links))
+	for _, l := range links {
+		res = append(res, l.URL)
+	}
+
+	return res
+}
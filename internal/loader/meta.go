@@ -0,0 +1,359 @@
+package loader
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"golang.org/x/net/html"
+)
+
+const (
+	TagMeta   = "meta"
+	TagScript = "script"
+	TagLink   = "link"
+
+	AttrProperty = "property"
+	AttrContent  = "content"
+	AttrType     = "type"
+	AttrName     = "name"
+
+	HeaderLastModified = "Last-Modified"
+	HeaderXRobotsTag   = "X-Robots-Tag"
+	HeaderLink         = "Link"
+
+	RelCanonical = "canonical"
+
+	MetaRobots        = "robots"
+	DirectiveNoIndex  = "noindex"
+	DirectiveNoFollow = "nofollow"
+	DirectiveNone     = "none"
+
+	// directiveUnavailableAfter is the only robots directive that has a value after a colon.
+	directiveUnavailableAfter = "unavailable_after"
+
+	PropertyModifiedTime = "article:modified_time"
+	TypeJSONLD           = "application/ld+json"
+	KeyDateModified      = "dateModified"
+	KeyGraph             = "@graph"
+)
+
+// dateLayouts are layouts of dates that may be found in page metadata.
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// getPageMeta collects page metadata from response headers and the page.
+// LastModified is taken from Last-Modified header, and if it's missing
+// from <meta property="article:modified_time"> or JSON-LD dateModified.
+func getPageMeta(header http.Header, node *html.Node) core.PageMeta {
+
+	var meta core.PageMeta
+
+	if lm := header.Get(HeaderLastModified); len(lm) > 0 {
+		t, err := http.ParseTime(lm)
+		if err != nil {
+			log.Printf("ERR: bad %v header [%v]: %v", HeaderLastModified, lm, err)
+		} else {
+			meta.LastModified = t
+		}
+	}
+
+	if meta.LastModified.IsZero() {
+		meta.LastModified = getContentModifiedTime(node)
+	}
+
+	return meta
+}
+
+// getContentModifiedTime looks for a page modification time in <meta> tags and JSON-LD scripts.
+// <meta> has a priority over JSON-LD.
+func getContentModifiedTime(node *html.Node) time.Time {
+
+	if node == nil {
+		return time.Time{}
+	}
+
+	var metaTime, jsonLDTime time.Time
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode {
+
+			switch n.Data {
+
+			case TagMeta:
+				if getAttr(n, AttrProperty) == PropertyModifiedTime && metaTime.IsZero() {
+					metaTime = parseDate(getAttr(n, AttrContent))
+				}
+
+			case TagScript:
+				if getAttr(n, AttrType) == TypeJSONLD && jsonLDTime.IsZero() && n.FirstChild != nil {
+					jsonLDTime = getJSONLDModifiedTime(n.FirstChild.Data)
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	if !metaTime.IsZero() {
+		return metaTime
+	}
+
+	return jsonLDTime
+}
+
+// getJSONLDModifiedTime returns the first dateModified value found in JSON-LD document.
+// A document may be an object, an array of objects or an object with @graph.
+func getJSONLDModifiedTime(src string) time.Time {
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(src), &doc); err != nil {
+		return time.Time{}
+	}
+
+	var findFunc func(interface{}) time.Time
+	findFunc = func(v interface{}) time.Time {
+
+		switch val := v.(type) {
+
+		case []interface{}:
+			for _, item := range val {
+				if t := findFunc(item); !t.IsZero() {
+					return t
+				}
+			}
+
+		case map[string]interface{}:
+			if s, ok := val[KeyDateModified].(string); ok {
+				if t := parseDate(s); !t.IsZero() {
+					return t
+				}
+			}
+
+			if graph, ok := val[KeyGraph]; ok {
+				return findFunc(graph)
+			}
+		}
+
+		return time.Time{}
+	}
+
+	return findFunc(doc)
+}
+
+// parseDate parses a date in one of supported layouts. It returns zero time if a date can't be parsed.
+func parseDate(s string) time.Time {
+
+	s = strings.TrimSpace(s)
+
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// getRobotsDirectives collects noindex and nofollow directives from <meta name="robots"> and X-Robots-Tag headers.
+// X-Robots-Tag values that are addressed to a specific user agent (e.g. "googlebot: noindex") are ignored.
+func getRobotsDirectives(header http.Header, node *html.Node) (bool, bool) {
+
+	var noIndex, noFollow bool
+
+	applyFunc := func(directives string) {
+		for _, d := range strings.Split(directives, ",") {
+			switch strings.ToLower(strings.TrimSpace(d)) {
+			case DirectiveNoIndex:
+				noIndex = true
+			case DirectiveNoFollow:
+				noFollow = true
+			case DirectiveNone:
+				noIndex = true
+				noFollow = true
+			}
+		}
+	}
+
+	for _, value := range header.Values(HeaderXRobotsTag) {
+		first, _, _ := strings.Cut(value, ",")
+		if agent, _, ok := strings.Cut(first, ":"); ok && strings.ToLower(strings.TrimSpace(agent)) != directiveUnavailableAfter {
+			continue
+		}
+
+		applyFunc(value)
+	}
+
+	if node == nil {
+		return noIndex, noFollow
+	}
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode && n.Data == TagMeta && strings.EqualFold(getAttr(n, AttrName), MetaRobots) {
+			applyFunc(getAttr(n, AttrContent))
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	return noIndex, noFollow
+}
+
+// getCanonical returns an absolute canonical URL of the page.
+// Link header has a priority over <link rel="canonical">. If a page has no canonical URL getCanonical returns an empty string.
+func getCanonical(header http.Header, node *html.Node, baseURL, pageURL string) string {
+
+	// Link header URLs are resolved against the page URL as they don't depend on <base>
+	for _, value := range header.Values(HeaderLink) {
+		if href := getLinkHeaderURL(value, RelCanonical); len(href) > 0 {
+			if links := updateLinksWithBase([]core.Link{{URL: href}}, pageURL, pageURL); len(links) > 0 {
+				return links[0].URL
+			}
+		}
+	}
+
+	if node == nil {
+		return ""
+	}
+
+	var href string
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if len(href) > 0 {
+			return
+		}
+
+		if n.Type == html.ElementNode && n.Data == TagLink && hasToken(getAttr(n, AttrRel), RelCanonical) {
+			href = strings.TrimSpace(getAttr(n, AttrHref))
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	if len(href) == 0 {
+		return ""
+	}
+
+	links := updateLinksWithBase([]core.Link{{URL: href}}, baseURL, pageURL)
+	if len(links) == 0 {
+		return ""
+	}
+
+	return links[0].URL
+}
+
+// getLinkHeaderURL returns a URL of Link header entry with the rel.
+// A header value is a comma-separated list of entries like <https://e.com/page>; rel="canonical".
+// Commas and semicolons inside <URL> and quoted strings don't split entries and params.
+func getLinkHeaderURL(value, rel string) string {
+
+	for _, entry := range splitLinkHeader(value, ',') {
+
+		params := splitLinkHeader(entry, ';')
+
+		href := strings.TrimSpace(params[0])
+		if !strings.HasPrefix(href, "<") || !strings.HasSuffix(href, ">") {
+			continue
+		}
+
+		for _, param := range params[1:] {
+			key, val, ok := strings.Cut(param, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), AttrRel) {
+				continue
+			}
+
+			if hasToken(strings.Trim(strings.TrimSpace(val), `"`), rel) {
+				return strings.TrimSuffix(strings.TrimPrefix(href, "<"), ">")
+			}
+		}
+	}
+
+	return ""
+}
+
+// splitLinkHeader splits a Link header value by the separator that is not inside <URL> or a quoted string.
+func splitLinkHeader(value string, sep byte) []string {
+
+	var (
+		res      []string
+		start    int
+		inURL    bool
+		inQuotes bool
+	)
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		switch {
+		case inQuotes:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inQuotes = false
+			}
+
+		case inURL:
+			inURL = c != '>'
+
+		case c == '"':
+			inQuotes = true
+
+		case c == '<':
+			inURL = true
+
+		case c == sep:
+			res = append(res, value[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(res, value[start:])
+}
+
+// hasToken checks if a space-separated list of tokens (like rel attribute value) has a token.
+func hasToken(list, token string) bool {
+	for _, t := range strings.Fields(list) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getAttr returns a value of the node attribute or an empty string if there's no such attribute.
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,216 @@
+package loader
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_getPageMeta(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		header  http.Header
+		page    []byte
+		expMeta core.PageMeta
+	}
+
+	tests := map[string]Test{
+		"Last-Modified header": {
+			header: http.Header{
+				"Last-Modified": {"Wed, 21 Oct 2015 07:28:00 GMT"},
+			},
+			page: pageWithModifiedMeta,
+			expMeta: core.PageMeta{
+				LastModified: time.Date(2015, 10, 21, 7, 28, 0, 0, time.UTC),
+			},
+		},
+
+		"meta tag": {
+			header: http.Header{},
+			page:   pageWithModifiedMeta,
+			expMeta: core.PageMeta{
+				LastModified: time.Date(2021, 3, 4, 10, 0, 0, 0, time.UTC),
+			},
+		},
+
+		"JSON-LD": {
+			header: http.Header{},
+			page:   pageWithJSONLD,
+			expMeta: core.PageMeta{
+				LastModified: time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+
+		"bad header": {
+			header: http.Header{
+				"Last-Modified": {"yesterday"},
+			},
+			page: pageWithJSONLD,
+			expMeta: core.PageMeta{
+				LastModified: time.Date(2022, 5, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+
+		"no metadata": {
+			header:  http.Header{},
+			page:    pageOK,
+			expMeta: core.PageMeta{},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			meta := getPageMeta(test.header, parsePage(test.page))
+
+			require.True(t, test.expMeta.LastModified.Equal(meta.LastModified),
+				"expected %v, got %v", test.expMeta.LastModified, meta.LastModified)
+		})
+	}
+}
+
+func TestLoader_getRobotsDirectives(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		header      http.Header
+		page        []byte
+		expNoIndex  bool
+		expNoFollow bool
+	}
+
+	tests := map[string]Test{
+		"meta tag": {
+			header:      http.Header{},
+			page:        pageWithRobotsMeta,
+			expNoIndex:  true,
+			expNoFollow: false,
+		},
+
+		"header": {
+			header: http.Header{
+				"X-Robots-Tag": {"nofollow"},
+			},
+			page:        pageOK,
+			expNoIndex:  false,
+			expNoFollow: true,
+		},
+
+		"none": {
+			header: http.Header{
+				"X-Robots-Tag": {"unavailable_after: 25 Jun 2010 15:00:00 PST, none"},
+			},
+			page:        pageOK,
+			expNoIndex:  true,
+			expNoFollow: true,
+		},
+
+		"header for other user agent": {
+			header: http.Header{
+				"X-Robots-Tag": {"googlebot: noindex, nofollow"},
+			},
+			page:        pageOK,
+			expNoIndex:  false,
+			expNoFollow: false,
+		},
+
+		"header and meta tag": {
+			header: http.Header{
+				"X-Robots-Tag": {"noarchive", "NoFollow"},
+			},
+			page:        pageWithRobotsMeta,
+			expNoIndex:  true,
+			expNoFollow: true,
+		},
+
+		"no directives": {
+			header:      http.Header{},
+			page:        pageOK,
+			expNoIndex:  false,
+			expNoFollow: false,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			noIndex, noFollow := getRobotsDirectives(test.header, parsePage(test.page))
+
+			require.Equal(t, test.expNoIndex, noIndex)
+			require.Equal(t, test.expNoFollow, noFollow)
+		})
+	}
+}
+
+func TestLoader_getCanonical(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		header       http.Header
+		page         []byte
+		expCanonical string
+	}
+
+	tests := map[string]Test{
+		"link tag": {
+			header:       http.Header{},
+			page:         pageWithCanonical,
+			expCanonical: "http://test.com/canonical/page",
+		},
+
+		"Link header": {
+			header: http.Header{
+				"Link": {`<http://hello.com/styles.css>; rel="preload", </header/canonical>; rel="canonical"`},
+			},
+			page:         pageWithCanonical,
+			expCanonical: "http://hello.com/header/canonical",
+		},
+
+		"Link header with commas and semicolons": {
+			header: http.Header{
+				"Link": {`<http://e.com/a,b;c>; title="x, rel=canonical; y"; rel=canonical, </other>; rel="preload"`},
+			},
+			page:         pageWithCanonical,
+			expCanonical: "http://e.com/a,b;c",
+		},
+
+		"Link header with quoted rel": {
+			header: http.Header{
+				"Link": {`</preload>; title="a \"quoted\", list"; rel="preload", </header/canonical>; rel="next canonical"`},
+			},
+			page:         pageWithCanonical,
+			expCanonical: "http://hello.com/header/canonical",
+		},
+
+		"no canonical": {
+			header:       http.Header{},
+			page:         pageOK,
+			expCanonical: "",
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			canonical := getCanonical(test.header, parsePage(test.page), "http://test.com/", "http://hello.com/page?utm_source=x")
+
+			require.Equal(t, test.expCanonical, canonical)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package loader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// RenderURLPlaceholder is replaced with an escaped page URL in ServiceRenderer endpoint.
+const RenderURLPlaceholder = "{url}"
+
+type (
+	// Renderer returns HTML of a page after client-side scripts have run, e.g. by a headless browser.
+	// The result is parsed instead of a static page body.
+	Renderer interface {
+		Render(ctx context.Context, pageURL string) ([]byte, error)
+	}
+
+	// ServiceRenderer renders pages with an external render service (like Rendertron or Prerender)
+	// that responds with rendered HTML to a GET request.
+	ServiceRenderer struct {
+		endpoint    string
+		client      *http.Client
+		maxBodySize int64
+	}
+)
+
+// NewServiceRenderer creates a renderer of a render service endpoint.
+// If the endpoint has RenderURLPlaceholder it's replaced with an escaped page URL,
+// otherwise a page URL is appended to the endpoint, e.g. http://localhost:3000/render/<page URL>.
+// Render requests use timeouts, proxy and TLS settings of the config. A rendered page is read up to
+// Config.MaxBodySize bytes and one more byte, so Loader can tell the page is truncated.
+func NewServiceRenderer(endpoint string, config Config) (*ServiceRenderer, error) {
+
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, fmt.Errorf("bad render service URL [%v]", endpoint)
+	}
+
+	config = setDefaults(config)
+
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServiceRenderer{
+		endpoint:    endpoint,
+		client:      client,
+		maxBodySize: config.MaxBodySize,
+	}, nil
+}
+
+// Render requests a rendered page from the service. Non-2xx responses are errors.
+func (r *ServiceRenderer) Render(ctx context.Context, pageURL string) ([]byte, error) {
+
+	renderURL := r.endpoint + pageURL
+	if strings.Contains(r.endpoint, RenderURLPlaceholder) {
+		renderURL = strings.ReplaceAll(r.endpoint, RenderURLPlaceholder, url.QueryEscape(pageURL))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, renderURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create render request: %w", err)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("render request failed: %w", err)
+	}
+
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("render service responded with status %v", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, r.maxBodySize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered page: %w", err)
+	}
+
+	return body, nil
+}
+
+// render returns a rendered page body if Config.Renderer is set, otherwise it returns the static body.
+// If rendering fails the static body is returned, so links that are present without scripts are still crawled.
+// The rendered body is limited by Config.MaxBodySize as well.
+func (l *Loader) render(ctx context.Context, pageURL string, resp *response) ([]byte, bool) {
+
+	if l.config.Renderer == nil {
+		return resp.body, resp.truncated
+	}
+
+	body, err := l.config.Renderer.Render(ctx, pageURL)
+	if err != nil {
+		log.Printf("ERR: failed to render page [%v], using the static page: %v", pageURL, err)
+		return resp.body, resp.truncated
+	}
+
+	truncated := int64(len(body)) > l.config.MaxBodySize
+	if truncated {
+		body = body[:l.config.MaxBodySize]
+	}
+
+	return body, truncated
+}
@@ -0,0 +1,202 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// stubRenderer returns the same page for all URLs and records rendered URLs.
+type stubRenderer struct {
+	page []byte
+	err  error
+
+	mu   sync.Mutex
+	urls []string
+}
+
+func (r *stubRenderer) Render(_ context.Context, pageURL string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.urls = append(r.urls, pageURL)
+
+	return r.page, r.err
+}
+
+func TestLoader_LoadPageRendered(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		contentType string
+		renderer    *stubRenderer
+		expLinks    []string
+		expRendered bool
+	}
+
+	staticPage := `<html><body><div id="app"></div><a href="/static">Static</a></body></html>`
+	renderedPage := `<html><body><div id="app"><a href="/spa/route">SPA route</a></div><a href="/static">Static</a></body></html>`
+
+	tests := map[string]Test{
+		"static": {
+			contentType: "text/html",
+			expLinks:    []string{"/static"},
+		},
+
+		"rendered": {
+			contentType: "text/html",
+			renderer:    &stubRenderer{page: []byte(renderedPage)},
+			expLinks:    []string{"/spa/route", "/static"},
+			expRendered: true,
+		},
+
+		"non-HTML is not rendered": {
+			contentType: "application/pdf",
+			renderer:    &stubRenderer{page: []byte(renderedPage)},
+			expLinks:    nil,
+		},
+
+		"render error falls back to static page": {
+			contentType: "text/html",
+			renderer:    &stubRenderer{err: errors.New("browser crashed")},
+			expLinks:    []string{"/static"},
+			expRendered: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(HeaderContentType, test.contentType)
+				_, _ = w.Write([]byte(staticPage)) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			config := Config{}
+			if test.renderer != nil {
+				config.Renderer = test.renderer
+			}
+
+			res, err := newLoader(t, config).LoadPage(ctx, server.URL+"/page")
+
+			if test.renderer != nil {
+				if test.expRendered {
+					require.Equal(t, []string{server.URL + "/page"}, test.renderer.urls)
+				} else {
+					require.Empty(t, test.renderer.urls)
+				}
+			}
+
+			require.NoError(t, err)
+
+			var expLinks []string
+			for _, l := range test.expLinks {
+				expLinks = append(expLinks, server.URL+l)
+			}
+
+			require.Equal(t, expLinks, linksToList(res.Links))
+		})
+	}
+}
+
+func TestServiceRenderer_Render(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		endpoint string
+		status   int
+		expPath  string
+		expQuery string
+		expErr   bool
+	}
+
+	tests := map[string]Test{
+		"appended URL": {
+			endpoint: "/render/",
+			status:   http.StatusOK,
+			expPath:  "/render/http://e.com/page",
+		},
+
+		"placeholder": {
+			endpoint: "/render?url={url}",
+			status:   http.StatusOK,
+			expPath:  "/render",
+			expQuery: "url=http%3A%2F%2Fe.com%2Fpage",
+		},
+
+		"error status": {
+			endpoint: "/render/",
+			status:   http.StatusBadGateway,
+			expPath:  "/render/http://e.com/page",
+			expErr:   true,
+		},
+	}
+
+	ctx := context.Background()
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			var path, query string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path, query = r.URL.Path, r.URL.RawQuery
+
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte("<html>rendered</html>")) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			renderer, err := NewServiceRenderer(server.URL+test.endpoint, Config{})
+			require.NoError(t, err)
+
+			res, err := renderer.Render(ctx, "http://e.com/page")
+
+			require.Equal(t, test.expPath, path)
+			require.Equal(t, test.expQuery, query)
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, "<html>rendered</html>", string(res))
+		})
+	}
+
+	_, err := NewServiceRenderer("localhost:3000", Config{})
+	require.Error(t, err)
+}
+
+func TestServiceRenderer_MaxBodySize(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("<html>rendered</html>")) //nolint:errcheck
+	}))
+	defer server.Close()
+
+	renderer, err := NewServiceRenderer(server.URL+"/render/", Config{MaxBodySize: 6})
+	require.NoError(t, err)
+
+	res, err := renderer.Render(context.Background(), "http://e.com/page")
+	require.NoError(t, err)
+	require.Equal(t, "<html>r", string(res))
+}
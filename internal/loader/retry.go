@@ -0,0 +1,159 @@
+package loader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+const HeaderRetryAfter = "Retry-After"
+
+// getPage loads a page retrying network errors, 429 and 5xx responses with exponential backoff.
+// Too long redirect chains and redirects out of redirectScope are not retried.
+// Retry-After header of a response is honored. Retries are limited by Config.MaxRetries per URL
+// and by Config.RetryBudget per crawl. If retries are exhausted getPage returns an error.
+func (l *Loader) getPage(
+	ctx context.Context,
+	pageURL string,
+	maxBodySize int64,
+	redirectScope func(*url.URL) bool,
+) (*response, error) {
+
+	for attempt := 0; ; attempt++ {
+
+		resp, err := l.doGet(ctx, pageURL, maxBodySize, redirectScope)
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if errors.Is(err, errTooManyRedirects) || errors.Is(err, core.ErrOutOfScope) {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+
+		switch {
+		case err != nil:
+
+		case isRetryableStatus(resp.statusCode):
+			err = fmt.Errorf("server responded with status %v", resp.statusCode)
+			retryAfter = parseRetryAfter(resp.header.Get(HeaderRetryAfter))
+
+		default:
+			return resp, nil
+		}
+
+		if attempt >= l.config.MaxRetries {
+			if attempt == 0 {
+				return nil, err
+			}
+
+			return nil, fmt.Errorf("failed after %d retries: %w", attempt, err)
+		}
+
+		if !l.takeRetry() {
+			return nil, fmt.Errorf("retry budget is exhausted: %w", err)
+		}
+
+		delay := l.backoff(attempt, retryAfter)
+
+		log.Printf("retrying [%v] in %v: %v", pageURL, delay, err)
+
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// takeRetry takes a retry from the crawl budget. It returns false if the budget is exhausted.
+func (l *Loader) takeRetry() bool {
+
+	if l.config.RetryBudget <= 0 {
+		return true
+	}
+
+	return atomic.AddInt64(&l.retries, 1) <= int64(l.config.RetryBudget)
+}
+
+// backoff returns a delay before the next attempt. It's RetryBaseDelay * 2^attempt limited by RetryMaxDelay
+// with a random jitter of a half of the delay. retryAfter is used instead if it's set.
+func (l *Loader) backoff(attempt int, retryAfter time.Duration) time.Duration {
+
+	if retryAfter > 0 {
+		if l.config.RetryMaxDelay > 0 && retryAfter > l.config.RetryMaxDelay {
+			return l.config.RetryMaxDelay
+		}
+
+		return retryAfter
+	}
+
+	delay := l.config.RetryBaseDelay << attempt
+	if delay <= 0 || (l.config.RetryMaxDelay > 0 && delay > l.config.RetryMaxDelay) {
+		delay = l.config.RetryMaxDelay
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		//nolint:gosec
+		delay = time.Duration(half + rand.Int63n(half+1))
+	}
+
+	return delay
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// parseRetryAfter parses Retry-After header value that is a number of seconds or an HTTP date.
+// It returns zero if the value is missing or invalid.
+func parseRetryAfter(value string) time.Duration {
+
+	value = strings.TrimSpace(value)
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+
+		return time.Duration(seconds) * time.Second
+	}
+
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0
+	}
+
+	if d := time.Until(t); d > 0 {
+		return d
+	}
+
+	return 0
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
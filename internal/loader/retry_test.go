@@ -0,0 +1,165 @@
+package loader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_getPageRetries(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		config      Config
+		statuses    []int
+		retryAfter  string
+		expErr      bool
+		expAttempts int32
+		expMinTime  time.Duration
+	}
+
+	tests := map[string]Test{
+		"OK": {
+			config:      Config{MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusOK},
+			expAttempts: 1,
+		},
+
+		"retry 5xx": {
+			config:      Config{MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			expAttempts: 3,
+		},
+
+		"retries exhausted": {
+			config:      Config{MaxRetries: 2, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusInternalServerError},
+			expErr:      true,
+			expAttempts: 3,
+		},
+
+		"no retries": {
+			config:      Config{},
+			statuses:    []int{http.StatusInternalServerError},
+			expErr:      true,
+			expAttempts: 1,
+		},
+
+		"4xx is not retried": {
+			config:      Config{MaxRetries: 3, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusNotFound},
+			expAttempts: 1,
+		},
+
+		"Retry-After": {
+			config:      Config{MaxRetries: 1, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:  "1",
+			expAttempts: 2,
+			expMinTime:  time.Second,
+		},
+
+		"Retry-After is limited": {
+			config:      Config{MaxRetries: 1, RetryBaseDelay: time.Millisecond, RetryMaxDelay: 10 * time.Millisecond},
+			statuses:    []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:  "120",
+			expAttempts: 2,
+		},
+
+		"budget": {
+			config:      Config{MaxRetries: 5, RetryBudget: 1, RetryBaseDelay: time.Millisecond},
+			statuses:    []int{http.StatusServiceUnavailable},
+			expErr:      true,
+			expAttempts: 2,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			var attempts int32
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&attempts, 1))
+				if n > len(test.statuses) {
+					n = len(test.statuses)
+				}
+
+				if len(test.retryAfter) > 0 {
+					w.Header().Set(HeaderRetryAfter, test.retryAfter)
+				}
+
+				w.WriteHeader(test.statuses[n-1])
+			}))
+			defer server.Close()
+
+			start := time.Now()
+
+			_, err := newLoader(t, test.config).getPage(context.Background(), server.URL, DefaultMaxBodySize, nil)
+
+			if test.expErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, test.expAttempts, atomic.LoadInt32(&attempts))
+			require.GreaterOrEqual(t, time.Since(start), test.expMinTime)
+		})
+	}
+}
+
+func TestLoader_getPageNetworkError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serverURL := server.URL
+	server.Close()
+
+	_, err := newLoader(t, Config{MaxRetries: 2, RetryBaseDelay: time.Millisecond}).getPage(context.Background(), serverURL, DefaultMaxBodySize, nil)
+	require.ErrorContains(t, err, "failed after 2 retries")
+}
+
+func TestLoader_backoff(t *testing.T) {
+	t.Parallel()
+
+	l := newLoader(t, Config{RetryBaseDelay: 100 * time.Millisecond, RetryMaxDelay: time.Second})
+
+	for attempt, expMax := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		delay := l.backoff(attempt, 0)
+
+		require.GreaterOrEqual(t, delay, expMax/2)
+		require.LessOrEqual(t, delay, expMax)
+	}
+
+	require.Equal(t, 500*time.Millisecond, l.backoff(0, 500*time.Millisecond))
+	require.Equal(t, time.Second, l.backoff(0, time.Hour))
+}
+
+func TestLoader_parseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, 120*time.Second, parseRetryAfter("120"))
+	require.Equal(t, time.Duration(0), parseRetryAfter(""))
+	require.Equal(t, time.Duration(0), parseRetryAfter("soon"))
+	require.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+
+	d := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	require.Greater(t, d, 59*time.Minute)
+}
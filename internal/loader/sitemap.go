@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// gzipMagic are first bytes of gzip data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+type (
+
+	// sitemapDoc is either a urlset or a sitemapindex document.
+	sitemapDoc struct {
+		URLs     []sitemapLoc `xml:"url"`
+		Sitemaps []sitemapLoc `xml:"sitemap"`
+	}
+
+	sitemapLoc struct {
+		Loc string `xml:"loc"`
+	}
+)
+
+// LoadSitemap loads and parses a sitemap or a sitemap index. Gzipped sitemaps are decompressed.
+func (l *Loader) LoadSitemap(ctx context.Context, sitemapURL string) (*core.Sitemap, error) {
+	resp, err := l.getPage(ctx, sitemapURL, MaxSitemapSize, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sitemap: %w", err)
+	}
+
+	if resp.statusCode < 200 || resp.statusCode >= 300 {
+		return nil, fmt.Errorf("sitemap [%v] responded with status %v", sitemapURL, resp.statusCode)
+	}
+
+	if resp.truncated {
+		return nil, fmt.Errorf("sitemap [%v] is larger than %v bytes", sitemapURL, MaxSitemapSize)
+	}
+
+	return parseSitemap(resp.body)
+}
+
+func parseSitemap(buf []byte) (*core.Sitemap, error) {
+
+	if bytes.HasPrefix(buf, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(buf))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+
+		// the limit applies to the uncompressed size, so a small gzip bomb can't take all the memory
+		buf, err = ioutil.ReadAll(io.LimitReader(zr, MaxSitemapSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress sitemap: %w", err)
+		}
+
+		if len(buf) > MaxSitemapSize {
+			return nil, fmt.Errorf("uncompressed sitemap is larger than %v bytes", MaxSitemapSize)
+		}
+	}
+
+	var doc sitemapDoc
+	if err := xml.Unmarshal(buf, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+
+	return &core.Sitemap{
+		URLs:     locsToList(doc.URLs),
+		Sitemaps: locsToList(doc.Sitemaps),
+	}, nil
+}
+
+func locsToList(locs []sitemapLoc) []string {
+
+	if len(locs) == 0 {
+		return nil
+	}
+
+	res := make([]string, 0, len(locs))
+
+	for _, l := range locs {
+		if loc := strings.TrimSpace(l.Loc); len(loc) > 0 {
+			res = append(res, loc)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,109 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_LoadSitemap(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		body   []byte
+		status int
+		expErr bool
+		expRes *core.Sitemap
+	}
+
+	tests := map[string]Test{
+		"urlset": {
+			body:   sitemapURLSet,
+			status: http.StatusOK,
+			expRes: &core.Sitemap{
+				URLs: []string{"http://e.com/page1", "http://e.com/page2"},
+			},
+		},
+
+		"sitemap index": {
+			body:   sitemapIndex,
+			status: http.StatusOK,
+			expRes: &core.Sitemap{
+				Sitemaps: []string{"http://e.com/sitemap-1.xml", "http://e.com/sitemap-2.xml.gz"},
+			},
+		},
+
+		"gzip": {
+			body:   gzipBytes(t, sitemapURLSet),
+			status: http.StatusOK,
+			expRes: &core.Sitemap{
+				URLs: []string{"http://e.com/page1", "http://e.com/page2"},
+			},
+		},
+
+		"not found": {
+			status: http.StatusNotFound,
+			expErr: true,
+		},
+
+		"not XML": {
+			body:   nonHTMLPage,
+			status: http.StatusOK,
+			expErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				_, _ = w.Write(test.body) //nolint:errcheck
+			}))
+			defer server.Close()
+
+			res, err := newLoader(t, Config{}).LoadSitemap(ctx, server.URL+"/sitemap.xml")
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expRes, res)
+		})
+	}
+}
+
+func TestLoader_parseSitemapGzipLimit(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseSitemap(gzipBytes(t, make([]byte, MaxSitemapSize+1)))
+	require.EqualError(t, err, fmt.Sprintf("uncompressed sitemap is larger than %v bytes", MaxSitemapSize))
+}
+
+func gzipBytes(t *testing.T, buf []byte) []byte {
+	t.Helper()
+
+	var res bytes.Buffer
+
+	zw := gzip.NewWriter(&res)
+	_, err := zw.Write(buf)
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return res.Bytes()
+}
@@ -56,5 +56,107 @@ var (
 
 <html/>
 
+`)
+
+	pageWithModifiedMeta = []byte(`<!DOCTYPE html>
+
+<html lang="en-US">
+
+<head>
+    <meta charset="utf-8">
+	<meta property="article:modified_time" content="2021-03-04T10:00:00+00:00">
+	<script type="application/ld+json">{"@context": "https://schema.org", "dateModified": "2022-05-06"}</script>
+</head>
+
+<body>
+	<a href="/rel/link">Relative link</a>
+<body/>
+
+<html/>
+
+`)
+
+	pageWithJSONLD = []byte(`<!DOCTYPE html>
+
+<html lang="en-US">
+
+<head>
+    <meta charset="utf-8">
+	<script type="application/ld+json">
+		{
+			"@context": "https://schema.org",
+			"@graph": [
+				{"@type": "WebSite", "name": "Test"},
+				{"@type": "WebPage", "dateModified": "2022-05-06"}
+			]
+		}
+	</script>
+</head>
+
+<body>
+	<a href="/rel/link">Relative link</a>
+<body/>
+
+<html/>
+
+`)
+
+	sitemapURLSet = []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>http://e.com/page1</loc>
+  </url>
+  <url>
+    <loc> http://e.com/page2 </loc>
+    <lastmod>2022-05-06</lastmod>
+  </url>
+</urlset>
+`)
+
+	sitemapIndex = []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap>
+    <loc>http://e.com/sitemap-1.xml</loc>
+  </sitemap>
+  <sitemap>
+    <loc>http://e.com/sitemap-2.xml.gz</loc>
+  </sitemap>
+</sitemapindex>
+`)
+
+	pageWithRobotsMeta = []byte(`<!DOCTYPE html>
+
+<html lang="en-US">
+
+<head>
+    <meta charset="utf-8">
+	<meta name="Robots" content="NoIndex, follow">
+</head>
+
+<body>
+	<a href="/follow">Link</a>
+	<a href="/nofollow" rel="external nofollow">Nofollow link</a>
+<body/>
+
+<html/>
+
+`)
+
+	pageWithCanonical = []byte(`<!DOCTYPE html>
+
+<html lang="en-US">
+
+<head>
+    <meta charset="utf-8">
+	<link rel="stylesheet" href="/styles.css">
+	<link rel="canonical" href="/canonical/page">
+</head>
+
+<body>
+	<a href="/rel/link">Relative link</a>
+<body/>
+
+<html/>
+
 `)
 )
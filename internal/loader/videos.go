@@ -0,0 +1,310 @@
+package loader
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"golang.org/x/net/html"
+)
+
+const (
+	TagVideo = "video"
+	TagTitle = "title"
+
+	AttrPoster = "poster"
+	AttrTitle  = "title"
+
+	MetaDescription = "description"
+
+	PropertyOGTitle       = "og:title"
+	PropertyOGDescription = "og:description"
+	PropertyOGVideoImage  = "og:video:image"
+
+	TypeVideoObject = "VideoObject"
+
+	KeyType         = "@type"
+	KeyName         = "name"
+	KeyDescription  = "description"
+	KeyThumbnailURL = "thumbnailUrl"
+	KeyContentURL   = "contentUrl"
+	KeyEmbedURL     = "embedUrl"
+	KeyDuration     = "duration"
+)
+
+var (
+	// youTubeEmbedRe matches YouTube player URLs and captures a video ID.
+	youTubeEmbedRe = regexp.MustCompile(`^https?://(?:www\.)?youtube(?:-nocookie)?\.com/embed/([\w-]+)`)
+
+	// vimeoEmbedRe matches Vimeo player URLs.
+	vimeoEmbedRe = regexp.MustCompile(`^https?://player\.vimeo\.com/video/\d+`)
+
+	// isoDurationRe matches ISO 8601 durations like PT1H2M3S that are used by schema.org.
+	isoDurationRe = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+)
+
+// getVideos returns videos of the page: <video> elements, YouTube and Vimeo iframes and JSON-LD VideoObjects.
+// JSON-LD VideoObject fills missing fields of a video with the same content or player URL,
+// other VideoObjects are separate videos. Missing thumbnails are taken from og:video:image,
+// missing titles and descriptions are taken from the page.
+// Relative URLs are resolved against the base URL.
+func getVideos(node *html.Node, baseURL, pageURL string) []core.Video {
+
+	if node == nil {
+		return nil
+	}
+
+	var videos, jsonLDVideos []core.Video
+	var pageTitle, ogTitle, pageDescription, ogDescription, ogImage string
+
+	resolve := func(u string) string {
+		if res := resolveURLs([]string{u}, baseURL, pageURL); len(res) > 0 {
+			return res[0]
+		}
+
+		return ""
+	}
+
+	var walkFunc func(*html.Node)
+	walkFunc = func(n *html.Node) {
+
+		if n.Type == html.ElementNode {
+
+			switch n.Data {
+
+			case TagVideo:
+				video := core.Video{
+					ThumbnailURL: resolve(getAttr(n, AttrPoster)),
+					Title:        strings.TrimSpace(getAttr(n, AttrTitle)),
+					ContentURL:   resolve(getAttr(n, AttrSrc)),
+				}
+
+				for c := n.FirstChild; c != nil && len(video.ContentURL) == 0; c = c.NextSibling {
+					if c.Type == html.ElementNode && c.Data == TagSource {
+						video.ContentURL = resolve(getAttr(c, AttrSrc))
+					}
+				}
+
+				if len(video.ContentURL) > 0 {
+					videos = append(videos, video)
+				}
+
+			case TagIframe:
+				if video, ok := getEmbeddedVideo(resolve(getAttr(n, AttrSrc))); ok {
+					video.Title = strings.TrimSpace(getAttr(n, AttrTitle))
+					videos = append(videos, video)
+				}
+
+			case TagTitle:
+				if n.FirstChild != nil && len(pageTitle) == 0 {
+					pageTitle = strings.TrimSpace(n.FirstChild.Data)
+				}
+
+			case TagMeta:
+				content := strings.TrimSpace(getAttr(n, AttrContent))
+
+				switch {
+				case getAttr(n, AttrProperty) == PropertyOGTitle:
+					ogTitle = content
+				case getAttr(n, AttrProperty) == PropertyOGDescription:
+					ogDescription = content
+				case getAttr(n, AttrProperty) == PropertyOGVideoImage:
+					ogImage = resolve(content)
+				case strings.EqualFold(getAttr(n, AttrName), MetaDescription):
+					pageDescription = content
+				}
+
+			case TagScript:
+				if getAttr(n, AttrType) == TypeJSONLD && n.FirstChild != nil {
+					for _, video := range getJSONLDVideos(n.FirstChild.Data) {
+						video.ThumbnailURL = resolve(video.ThumbnailURL)
+						video.ContentURL = resolve(video.ContentURL)
+						video.PlayerURL = resolve(video.PlayerURL)
+						jsonLDVideos = append(jsonLDVideos, video)
+					}
+				}
+			}
+		}
+
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walkFunc(c)
+		}
+	}
+
+	walkFunc(node)
+
+	videos = mergeVideos(videos, jsonLDVideos)
+
+	if len(ogTitle) > 0 {
+		pageTitle = ogTitle
+	}
+
+	if len(ogDescription) > 0 {
+		pageDescription = ogDescription
+	}
+
+	for i := range videos {
+		videos[i].ThumbnailURL = firstNonEmpty(videos[i].ThumbnailURL, ogImage)
+		videos[i].Title = firstNonEmpty(videos[i].Title, pageTitle)
+		videos[i].Description = firstNonEmpty(videos[i].Description, pageDescription)
+	}
+
+	return videos
+}
+
+// getEmbeddedVideo returns a video of YouTube or Vimeo player URL. YouTube videos get a thumbnail by a video ID.
+func getEmbeddedVideo(src string) (core.Video, bool) {
+
+	if m := youTubeEmbedRe.FindStringSubmatch(src); m != nil {
+		return core.Video{
+			PlayerURL:    src,
+			ThumbnailURL: "https://i.ytimg.com/vi/" + m[1] + "/hqdefault.jpg",
+		}, true
+	}
+
+	if vimeoEmbedRe.MatchString(src) {
+		return core.Video{PlayerURL: src}, true
+	}
+
+	return core.Video{}, false
+}
+
+// mergeVideos fills missing fields of videos with JSON-LD videos that have the same content or player URL.
+// JSON-LD videos that don't match any video are appended.
+func mergeVideos(videos, jsonLDVideos []core.Video) []core.Video {
+
+	for _, ld := range jsonLDVideos {
+
+		matched := false
+
+		for i := range videos {
+			v := &videos[i]
+
+			if (len(v.ContentURL) == 0 || v.ContentURL != ld.ContentURL) && (len(v.PlayerURL) == 0 || v.PlayerURL != ld.PlayerURL) {
+				continue
+			}
+
+			v.ThumbnailURL = firstNonEmpty(v.ThumbnailURL, ld.ThumbnailURL)
+			v.Title = firstNonEmpty(v.Title, ld.Title)
+			v.Description = firstNonEmpty(v.Description, ld.Description)
+			v.ContentURL = firstNonEmpty(v.ContentURL, ld.ContentURL)
+			v.PlayerURL = firstNonEmpty(v.PlayerURL, ld.PlayerURL)
+
+			if v.Duration == 0 {
+				v.Duration = ld.Duration
+			}
+
+			matched = true
+		}
+
+		if !matched {
+			videos = append(videos, ld)
+		}
+	}
+
+	return videos
+}
+
+// getJSONLDVideos returns all VideoObjects of JSON-LD document.
+// A document may be an object, an array of objects or an object with @graph.
+func getJSONLDVideos(src string) []core.Video {
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(src), &doc); err != nil {
+		return nil
+	}
+
+	var videos []core.Video
+
+	var findFunc func(interface{})
+	findFunc = func(v interface{}) {
+
+		switch val := v.(type) {
+
+		case []interface{}:
+			for _, item := range val {
+				findFunc(item)
+			}
+
+		case map[string]interface{}:
+			if jsonLDString(val[KeyType]) == TypeVideoObject {
+				videos = append(videos, core.Video{
+					ThumbnailURL: jsonLDString(val[KeyThumbnailURL]),
+					Title:        jsonLDString(val[KeyName]),
+					Description:  jsonLDString(val[KeyDescription]),
+					ContentURL:   jsonLDString(val[KeyContentURL]),
+					PlayerURL:    jsonLDString(val[KeyEmbedURL]),
+					Duration:     parseISODuration(jsonLDString(val[KeyDuration])),
+				})
+			}
+
+			if graph, ok := val[KeyGraph]; ok {
+				findFunc(graph)
+			}
+		}
+	}
+
+	findFunc(doc)
+
+	return videos
+}
+
+// jsonLDString returns a string value or the first string of an array value.
+func jsonLDString(v interface{}) string {
+
+	switch val := v.(type) {
+
+	case string:
+		return strings.TrimSpace(val)
+
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok {
+				return strings.TrimSpace(s)
+			}
+		}
+	}
+
+	return ""
+}
+
+// parseISODuration parses ISO 8601 duration like PT1M30S. It returns zero if a duration can't be parsed.
+func parseISODuration(s string) time.Duration {
+
+	m := isoDurationRe.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+	if m == nil {
+		return 0
+	}
+
+	var d time.Duration
+
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	for i, unit := range units {
+		if len(m[i+1]) == 0 {
+			continue
+		}
+
+		v, err := strconv.ParseFloat(m[i+1], 64)
+		if err != nil {
+			return 0
+		}
+
+		d += time.Duration(v * float64(unit))
+	}
+
+	return d
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,138 @@
+package loader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestLoader_getVideos(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		page      string
+		expVideos []core.Video
+	}
+
+	tests := map[string]Test{
+		"video element": {
+			page: `<html><head><title>Tutorial</title><meta name="description" content="How to crawl"></head><body>
+<video poster="/poster.jpg"><source src="/video.webm" type="video/webm"><source src="/video.mp4"></video>
+<video src="videos/intro.mp4" title="Intro"></video>
+</body></html>`,
+			expVideos: []core.Video{
+				{
+					ThumbnailURL: "http://e.com/poster.jpg",
+					Title:        "Tutorial",
+					Description:  "How to crawl",
+					ContentURL:   "http://e.com/video.webm",
+				},
+				{
+					Title:       "Intro",
+					Description: "How to crawl",
+					ContentURL:  "http://e.com/dir/videos/intro.mp4",
+				},
+			},
+		},
+
+		"embedded players": {
+			page: `<html><head>
+<meta property="og:title" content="OG title">
+<meta property="og:description" content="OG description">
+<meta property="og:video:image" content="https://e.com/og-video.jpg">
+</head><body>
+<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ?rel=0" title="YouTube video"></iframe>
+<iframe src="https://player.vimeo.com/video/123456"></iframe>
+<iframe src="https://maps.google.com/embed"></iframe>
+</body></html>`,
+			expVideos: []core.Video{
+				{
+					ThumbnailURL: "https://i.ytimg.com/vi/dQw4w9WgXcQ/hqdefault.jpg",
+					Title:        "YouTube video",
+					Description:  "OG description",
+					PlayerURL:    "https://www.youtube.com/embed/dQw4w9WgXcQ?rel=0",
+				},
+				{
+					ThumbnailURL: "https://e.com/og-video.jpg",
+					Title:        "OG title",
+					Description:  "OG description",
+					PlayerURL:    "https://player.vimeo.com/video/123456",
+				},
+			},
+		},
+
+		"JSON-LD": {
+			page: `<html><head><script type="application/ld+json">
+{"@graph": [
+	{"@type": "VideoObject", "name": "LD video", "description": "LD description",
+		"thumbnailUrl": ["/ld-thumb.jpg"], "contentUrl": "/video.mp4", "duration": "PT1M30S"},
+	{"@type": "VideoObject", "name": "Other", "description": "Other video",
+		"thumbnailUrl": "/other.jpg", "embedUrl": "https://player.vimeo.com/video/1", "duration": "PT2H"}
+]}
+</script></head><body><video src="/video.mp4" title="Page video"></video></body></html>`,
+			expVideos: []core.Video{
+				{
+					ThumbnailURL: "http://e.com/ld-thumb.jpg",
+					Title:        "Page video",
+					Description:  "LD description",
+					ContentURL:   "http://e.com/video.mp4",
+					Duration:     90 * time.Second,
+				},
+				{
+					ThumbnailURL: "http://e.com/other.jpg",
+					Title:        "Other",
+					Description:  "Other video",
+					PlayerURL:    "https://player.vimeo.com/video/1",
+					Duration:     2 * time.Hour,
+				},
+			},
+		},
+
+		"no videos": {
+			page:      `<html><body><video></video><iframe src="/frame"></iframe></body></html>`,
+			expVideos: nil,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			videos := getVideos(parsePage([]byte(test.page)), "http://e.com/dir/page", "http://e.com/dir/page")
+
+			require.Equal(t, test.expVideos, videos)
+		})
+	}
+}
+
+func TestLoader_parseISODuration(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]time.Duration{
+		"PT1M30S":   90 * time.Second,
+		"PT2H":      2 * time.Hour,
+		"P1DT1H":    25 * time.Hour,
+		"pt0.5s":    500 * time.Millisecond,
+		"PT":        0,
+		"1:30":      0,
+		"":          0,
+		"PT1H2M3S":  time.Hour + 2*time.Minute + 3*time.Second,
+		"PT1M 30S ": 0,
+	}
+
+	//nolint:paralleltest
+	for src, expDuration := range tests {
+		src, expDuration := src, expDuration
+
+		t.Run(src, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, expDuration, parseISODuration(src))
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package pattern
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// RegexpPrefix marks a pattern as a regular expression.
+const RegexpPrefix = "re:"
+
+type (
+
+	// Pattern matches strings against a glob or a regular expression.
+	// Patterns with RegexpPrefix are regular expressions and match any part of a string,
+	// other patterns are globs that match a whole string.
+	// Glob supports * (any symbols but /), ** (any symbols) and ? (any symbol but /).
+	Pattern struct {
+		src string
+		re  *regexp.Regexp
+	}
+)
+
+// Compile parses a pattern.
+func Compile(expr string) (*Pattern, error) {
+
+	var reExpr string
+
+	if strings.HasPrefix(expr, RegexpPrefix) {
+		reExpr = strings.TrimPrefix(expr, RegexpPrefix)
+	} else {
+		reExpr = globToRegexp(expr)
+	}
+
+	re, err := regexp.Compile(reExpr)
+	if err != nil {
+		return nil, fmt.Errorf("bad pattern [%v]: %w", expr, err)
+	}
+
+	return &Pattern{
+		src: expr,
+		re:  re,
+	}, nil
+}
+
+// CompileList parses a list of patterns.
+func CompileList(exprs []string) ([]*Pattern, error) {
+
+	res := make([]*Pattern, 0, len(exprs))
+
+	for _, expr := range exprs {
+		p, err := Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, p)
+	}
+
+	return res, nil
+}
+
+// Match reports whether the string matches the pattern.
+func (p *Pattern) Match(s string) bool {
+	return p.re.MatchString(s)
+}
+
+// MatchAny reports whether the string matches any of patterns.
+func MatchAny(patterns []*Pattern, s string) bool {
+	for _, p := range patterns {
+		if p.Match(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Pattern) String() string {
+	return p.src
+}
+
+// PathAndQuery returns an escaped URL path with a query, that is a string URL patterns are matched against.
+func PathAndQuery(u *url.URL) string {
+
+	res := u.EscapedPath()
+	if len(u.RawQuery) > 0 {
+		res += "?" + u.RawQuery
+	}
+
+	return res
+}
+
+func globToRegexp(glob string) string {
+
+	var sb strings.Builder
+
+	sb.WriteString("^")
+
+	for i := 0; i < len(glob); i++ {
+
+		switch c := glob[i]; c {
+
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+
+		case '?':
+			sb.WriteString("[^/]")
+
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return sb.String()
+}
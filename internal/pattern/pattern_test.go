@@ -0,0 +1,61 @@
+package pattern
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_Match(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		pattern string
+		src     string
+		expRes  bool
+	}
+
+	tests := map[string]Test{
+		"exact":                  {pattern: "/about", src: "/about", expRes: true},
+		"exact mismatch":         {pattern: "/about", src: "/about/team", expRes: false},
+		"star":                   {pattern: "/blog/*", src: "/blog/post-1", expRes: true},
+		"star doesn't cross /":   {pattern: "/blog/*", src: "/blog/tag/go", expRes: false},
+		"double star":            {pattern: "/blog/**", src: "/blog/tag/go", expRes: true},
+		"question":               {pattern: "/page-?", src: "/page-1", expRes: true},
+		"query":                  {pattern: "/search*", src: "/search?q=go", expRes: true},
+		"special symbols quoted": {pattern: "/a.b", src: "/aXb", expRes: false},
+		"regexp":                 {pattern: `re:[?&]page=\d+`, src: "/blog?page=2", expRes: true},
+		"regexp mismatch":        {pattern: `re:[?&]page=\d+`, src: "/blog?pages=2", expRes: false},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := Compile(test.pattern)
+			require.NoError(t, err)
+
+			require.Equal(t, test.expRes, p.Match(test.src))
+		})
+	}
+}
+
+func TestPattern_CompileError(t *testing.T) {
+	t.Parallel()
+
+	_, err := CompileList([]string{"/ok/*", "re:[a-"})
+	require.Error(t, err)
+}
+
+func TestPathAndQuery(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("http://e.com/blog/a%20b?page=2#top")
+	require.NoError(t, err)
+
+	require.Equal(t, "/blog/a%20b?page=2", PathAndQuery(u))
+}
@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+
+	// Limiter limits requests per host. A request should call Wait() before it's sent
+	// and call a returned release function when it's finished.
+	Limiter struct {
+		config Config
+
+		hosts map[string]*hostLimiter
+		mu    sync.Mutex
+	}
+
+	Config struct {
+		// RPS is a max number of requests per second to a host. Zero means no limit.
+		RPS float64
+
+		// MaxInFlight is a max number of concurrent requests to a host. Zero means no limit.
+		MaxInFlight int
+
+		// Jitter is a max random delay that is added to every request, so requests don't come at regular intervals.
+		Jitter time.Duration
+	}
+
+	hostLimiter struct {
+		// next is the earliest time of the next request
+		next time.Time
+		mu   sync.Mutex
+
+		// minInterval is a min interval between requests set by SetMinInterval()
+		minInterval time.Duration
+
+		// slots is a semaphore of in-flight requests. It's nil if there's no limit.
+		slots chan struct{}
+	}
+)
+
+func New(config Config) *Limiter {
+	return &Limiter{
+		config: config,
+		hosts:  make(map[string]*hostLimiter),
+	}
+}
+
+// SetMinInterval sets a min interval between requests to the host, e.g. robots.txt Crawl-delay.
+func (l *Limiter) SetMinInterval(host string, interval time.Duration) {
+
+	hl := l.getHost(host)
+
+	hl.mu.Lock()
+	hl.minInterval = interval
+	hl.mu.Unlock()
+}
+
+// Wait blocks until a request to the host is allowed. The interval between requests is the largest of
+// 1/RPS and the host interval set by SetMinInterval(), plus a random jitter.
+// A caller should call the release function when the request is finished.
+// Wait returns an error if the context is done.
+func (l *Limiter) Wait(ctx context.Context, host string) (func(), error) {
+
+	hl := l.getHost(host)
+
+	release := func() {}
+
+	if hl.slots != nil {
+		select {
+		case hl.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-hl.slots })
+		}
+	}
+
+	interval := hl.getMinInterval()
+
+	if l.config.RPS > 0 {
+		if rpsInterval := time.Duration(float64(time.Second) / l.config.RPS); rpsInterval > interval {
+			interval = rpsInterval
+		}
+	}
+
+	var jitter time.Duration
+	if l.config.Jitter > 0 {
+		//nolint:gosec
+		jitter = time.Duration(rand.Int63n(int64(l.config.Jitter)))
+	}
+
+	slot := hl.reserve(interval)
+
+	delay := time.Until(slot) + jitter
+	if delay <= 0 {
+		return release, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return release, nil
+
+	case <-ctx.Done():
+		hl.cancel(slot, interval)
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Limiter) getHost(host string) *hostLimiter {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hl, ok := l.hosts[host]
+	if !ok {
+		hl = &hostLimiter{}
+
+		if l.config.MaxInFlight > 0 {
+			hl.slots = make(chan struct{}, l.config.MaxInFlight)
+		}
+
+		l.hosts[host] = hl
+	}
+
+	return hl
+}
+
+func (hl *hostLimiter) getMinInterval() time.Duration {
+
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	return hl.minInterval
+}
+
+// reserve books the earliest available time for a request.
+func (hl *hostLimiter) reserve(interval time.Duration) time.Time {
+
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	now := time.Now()
+
+	slot := hl.next
+	if slot.Before(now) {
+		slot = now
+	}
+
+	hl.next = slot.Add(interval)
+
+	return slot
+}
+
+// cancel returns a reserved time if it's the last reservation, so canceled requests don't delay next ones.
+func (hl *hostLimiter) cancel(slot time.Time, interval time.Duration) {
+
+	hl.mu.Lock()
+	defer hl.mu.Unlock()
+
+	if hl.next.Equal(slot.Add(interval)) {
+		hl.next = slot
+	}
+}
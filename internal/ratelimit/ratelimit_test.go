@@ -0,0 +1,173 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Wait(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		config    Config
+		nRequests int
+		expMin    time.Duration
+		expMax    time.Duration
+	}
+
+	tests := map[string]Test{
+		"no limit": {
+			config:    Config{},
+			nRequests: 10,
+			expMin:    0,
+			expMax:    20 * time.Millisecond,
+		},
+
+		"rps": {
+			config:    Config{RPS: 50},
+			nRequests: 5,
+			expMin:    80 * time.Millisecond,
+			expMax:    200 * time.Millisecond,
+		},
+
+		"jitter": {
+			config:    Config{Jitter: 20 * time.Millisecond},
+			nRequests: 5,
+			expMin:    0,
+			expMax:    100 * time.Millisecond,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			l := New(test.config)
+
+			start := time.Now()
+
+			for i := 0; i < test.nRequests; i++ {
+				release, err := l.Wait(context.Background(), "e.com")
+				require.NoError(t, err)
+				release()
+			}
+
+			elapsed := time.Since(start)
+
+			require.GreaterOrEqual(t, elapsed, test.expMin)
+			require.Less(t, elapsed, test.expMax)
+		})
+	}
+}
+
+func TestLimiter_SetMinInterval(t *testing.T) {
+	t.Parallel()
+
+	l := New(Config{RPS: 1000})
+	l.SetMinInterval("e.com", 30*time.Millisecond)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		release, err := l.Wait(context.Background(), "e.com")
+		require.NoError(t, err)
+		release()
+	}
+
+	require.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	start = time.Now()
+
+	release, err := l.Wait(context.Background(), "other.com")
+	require.NoError(t, err)
+	release()
+
+	require.Less(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestLimiter_WaitHosts(t *testing.T) {
+	t.Parallel()
+
+	l := New(Config{RPS: 10})
+
+	start := time.Now()
+
+	for _, host := range []string{"a.com", "b.com", "c.com"} {
+		release, err := l.Wait(context.Background(), host)
+		require.NoError(t, err)
+		release()
+	}
+
+	// the first request to every host is not delayed
+	require.Less(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestLimiter_MaxInFlight(t *testing.T) {
+	t.Parallel()
+
+	l := New(Config{MaxInFlight: 2})
+
+	var inFlight, maxInFlight int32
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			release, err := l.Wait(context.Background(), "e.com")
+			require.NoError(t, err)
+
+			n := atomic.AddInt32(&inFlight, 1)
+			for {
+				m := atomic.LoadInt32(&maxInFlight)
+				if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			atomic.AddInt32(&inFlight, -1)
+			release()
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, int32(2), maxInFlight)
+}
+
+func TestLimiter_WaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	l := New(Config{MaxInFlight: 1, RPS: 1})
+
+	release, err := l.Wait(context.Background(), "e.com")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = l.Wait(ctx, "e.com")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+
+	// the slot is released, but the next request has to wait for the rate limit
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel2()
+
+	_, err = l.Wait(ctx2, "e.com")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,57 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// csvHeader is a header row of a CSV report.
+var csvHeader = []string{"url", "depth", "parent", "status", "exclude_reason"}
+
+// CSVWriter saves all pages of the references tree as CSV rows including excluded pages.
+// Status is empty if it's unknown.
+type CSVWriter struct {
+	config Config
+}
+
+func NewCSVWriter(config Config) *CSVWriter {
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
+	return &CSVWriter{
+		config: config,
+	}
+}
+
+func (w *CSVWriter) Save(tree *core.PageItem) error {
+
+	var buf bytes.Buffer
+
+	cw := csv.NewWriter(&buf)
+
+	rows := [][]string{csvHeader}
+
+	walkTree(tree, func(page, parent *core.PageItem) {
+
+		var parentURL, status string
+
+		if parent != nil {
+			parentURL = parent.URL
+		}
+
+		if page.StatusCode != 0 {
+			status = strconv.Itoa(page.StatusCode)
+		}
+
+		rows = append(rows, []string{page.URL, strconv.Itoa(page.Level), parentURL, status, page.ExcludeReason})
+	})
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+
+	return writeFile(w.config.FileName, buf.Bytes(), w.config.Compress)
+}
@@ -0,0 +1,129 @@
+package reporter
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+const (
+	FormatXML  = "xml"
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatText = "txt"
+	FormatHTML = "html"
+)
+
+// FormatFunc creates a reporter that saves a tree in some format.
+type FormatFunc func(config Config) core.Reporter
+
+// formats are reporters constructors by format names.
+var formats = map[string]FormatFunc{
+	FormatXML:  func(config Config) core.Reporter { return New(config) },
+	FormatJSON: func(config Config) core.Reporter { return NewJSONWriter(config) },
+	FormatCSV:  func(config Config) core.Reporter { return NewCSVWriter(config) },
+	FormatText: func(config Config) core.Reporter { return NewTextWriter(config) },
+	FormatHTML: func(config Config) core.Reporter { return NewHTMLWriter(config) },
+}
+
+// formatExtensions are format names by file extensions.
+var formatExtensions = map[string]string{
+	".xml":  FormatXML,
+	".json": FormatJSON,
+	".csv":  FormatCSV,
+	".txt":  FormatText,
+	".html": FormatHTML,
+	".htm":  FormatHTML,
+}
+
+// RegisterFormat adds a format or replaces an existing one. Files with extensions (like ".xml") get the format
+// if it's not set explicitly. RegisterFormat should be called before reporters are created, e.g. in init().
+func RegisterFormat(name string, f FormatFunc, extensions ...string) {
+	formats[name] = f
+
+	for _, ext := range extensions {
+		formatExtensions[strings.ToLower(ext)] = name
+	}
+}
+
+// Formats returns sorted names of all formats.
+func Formats() []string {
+
+	res := make([]string, 0, len(formats))
+	for name := range formats {
+		res = append(res, name)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// FormatOf returns a format of a file by its extension. GzipExt is ignored.
+// It returns an empty string if the extension is unknown.
+func FormatOf(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSuffix(fileName, GzipExt)))
+	return formatExtensions[ext]
+}
+
+// NewFormat creates a reporter of the format. If the format is empty it's chosen by FileName extension,
+// and XML sitemap is saved if the extension is unknown. It returns an error if the format is unknown.
+func NewFormat(format string, config Config) (core.Reporter, error) {
+
+	if len(format) == 0 {
+		format = FormatOf(config.FileName)
+	}
+
+	if len(format) == 0 {
+		format = FormatXML
+	}
+
+	f, ok := formats[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format [%v], supported formats are %v", format, strings.Join(Formats(), ", "))
+	}
+
+	return f(config), nil
+}
+
+// walkTree calls f for every page of the tree, parents go before children.
+// Children are visited in URLs order, so the result doesn't depend on a crawl order.
+func walkTree(root *core.PageItem, f func(page, parent *core.PageItem)) {
+
+	var walkFunc func(page, parent *core.PageItem)
+	walkFunc = func(page, parent *core.PageItem) {
+
+		f(page, parent)
+
+		for _, child := range sortedChildren(page) {
+			walkFunc(child, page)
+		}
+	}
+
+	walkFunc(root, nil)
+}
+
+// sortedChildren returns page children sorted by URLs.
+func sortedChildren(page *core.PageItem) []*core.PageItem {
+
+	children := make([]*core.PageItem, len(page.Children))
+	copy(children, page.Children)
+
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].URL < children[j].URL
+	})
+
+	return children
+}
+
+// gzipFileName adds GzipExt to the file name if compress is true and the name doesn't have it.
+func gzipFileName(fileName string, compress bool) string {
+	if compress && !strings.HasSuffix(fileName, GzipExt) {
+		return fileName + GzipExt
+	}
+
+	return fileName
+}
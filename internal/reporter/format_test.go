@@ -0,0 +1,172 @@
+package reporter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// formatTree is a tree that format tests save.
+var formatTree = &core.PageItem{
+	URL:        "http://e.com",
+	StatusCode: 200,
+	Meta:       core.PageMeta{LastModified: time.Date(2022, 5, 6, 10, 0, 0, 0, time.UTC)},
+	Children: []*core.PageItem{
+		{URL: "http://e.com/b", Level: 1, StatusCode: 404, ExcludeReason: "status 404"},
+		{URL: "http://e.com/a", Level: 1, StatusCode: 200, Children: []*core.PageItem{
+			{URL: "http://e.com/a/1?x=1&y=<2>", Level: 2, StatusCode: 200},
+		}},
+	},
+}
+
+func TestReporter_NewFormat(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		format   string
+		fileName string
+		expType  core.Reporter
+		expErr   bool
+	}
+
+	tests := map[string]Test{
+		"by extension": {
+			fileName: "sitemap.json",
+			expType:  &JSONWriter{},
+		},
+
+		"by compressed file extension": {
+			fileName: "urllist.TXT.gz",
+			expType:  &TextWriter{},
+		},
+
+		"unknown extension": {
+			fileName: "sitemap",
+			expType:  &Reporter{},
+		},
+
+		"by format": {
+			format:   "CSV",
+			fileName: "sitemap.xml",
+			expType:  &CSVWriter{},
+		},
+
+		"HTML": {
+			fileName: "sitemap.htm",
+			expType:  &HTMLWriter{},
+		},
+
+		"unknown format": {
+			format:   "yaml",
+			fileName: "sitemap.yaml",
+			expErr:   true,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := NewFormat(test.format, Config{FileName: test.fileName})
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.IsType(t, test.expType, r)
+		})
+	}
+}
+
+// RegisterFormat changes the global registry, so the test isn't parallel.
+//
+//nolint:paralleltest
+func TestReporter_RegisterFormat(t *testing.T) {
+	RegisterFormat("test-md", func(config Config) core.Reporter { return NewTextWriter(config) }, ".test-md")
+
+	require.Contains(t, Formats(), "test-md")
+	require.Equal(t, "test-md", FormatOf("sitemap.test-md"))
+
+	r, err := NewFormat("", Config{FileName: "sitemap.test-md"})
+	require.NoError(t, err)
+	require.IsType(t, &TextWriter{}, r)
+}
+
+func TestJSONWriter_Save(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.json")
+
+	require.NoError(t, NewJSONWriter(Config{FileName: fileName, Compress: true}).Save(formatTree))
+
+	var root JSONPage
+	require.NoError(t, json.Unmarshal(readFile(t, fileName+GzipExt), &root))
+
+	require.Equal(t, JSONPage{
+		URL:     "http://e.com",
+		Depth:   0,
+		Status:  200,
+		LastMod: "2022-05-06T10:00:00Z",
+		Children: []*JSONPage{
+			{URL: "http://e.com/a", Depth: 1, Parent: "http://e.com", Status: 200, Children: []*JSONPage{
+				{URL: "http://e.com/a/1?x=1&y=<2>", Depth: 2, Parent: "http://e.com/a", Status: 200},
+			}},
+			{URL: "http://e.com/b", Depth: 1, Parent: "http://e.com", Status: 404, ExcludeReason: "status 404"},
+		},
+	}, root)
+}
+
+func TestCSVWriter_Save(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.csv")
+
+	require.NoError(t, NewCSVWriter(Config{FileName: fileName}).Save(formatTree))
+
+	rows, err := csv.NewReader(strings.NewReader(string(readFile(t, fileName)))).ReadAll()
+	require.NoError(t, err)
+
+	require.Equal(t, [][]string{
+		{"url", "depth", "parent", "status", "exclude_reason"},
+		{"http://e.com", "0", "", "200", ""},
+		{"http://e.com/a", "1", "http://e.com", "200", ""},
+		{"http://e.com/a/1?x=1&y=<2>", "2", "http://e.com/a", "200", ""},
+		{"http://e.com/b", "1", "http://e.com", "404", "status 404"},
+	}, rows)
+}
+
+func TestTextWriter_Save(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(t.TempDir(), "urllist.txt")
+
+	require.NoError(t, NewTextWriter(Config{FileName: fileName}).Save(formatTree))
+
+	require.Equal(t, "http://e.com\nhttp://e.com/a\nhttp://e.com/a/1?x=1&y=%3C2%3E\n", string(readFile(t, fileName)))
+}
+
+func TestHTMLWriter_Save(t *testing.T) {
+	t.Parallel()
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.html")
+
+	require.NoError(t, NewHTMLWriter(Config{FileName: fileName}).Save(formatTree))
+
+	page := string(readFile(t, fileName))
+
+	require.Contains(t, page, `<title>Site map of http://e.com</title>`)
+	require.Contains(t, page, `<a href="http://e.com/a/1?x=1&amp;y=%3c2%3e">http://e.com/a/1?x=1&amp;y=&lt;2&gt;</a>`)
+	require.Contains(t, page, `<li class="excluded"><a href="http://e.com/b">http://e.com/b</a> (status 404)</li>`)
+	require.Less(t, strings.Index(page, "http://e.com/a/1"), strings.Index(page, "http://e.com/b"))
+}
@@ -0,0 +1,49 @@
+package reporter
+
+import (
+	"log"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+const (
+	// XhtmlXmlns is a namespace of <xhtml:link> alternates.
+	XhtmlXmlns = "http://www.w3.org/1999/xhtml"
+
+	RelAlternate = "alternate"
+)
+
+// AlternateLink is an <xhtml:link rel="alternate" hreflang="..." href="..."/> entry of a URL.
+type AlternateLink struct {
+	Rel      string `xml:"rel,attr"`
+	Hreflang string `xml:"hreflang,attr"`
+	Href     string `xml:"href,attr"`
+}
+
+// buildAlternates returns alternate entries of a page: all alternates of its cluster including the page itself.
+// A page gets no entries if it's not in the cluster or the cluster has no other alternates.
+func buildAlternates(page *core.PageItem) []AlternateLink {
+
+	cluster := page.Cluster
+
+	if cluster == nil || len(cluster.Alternates) < 2 || !cluster.Has(page.URL) {
+		return nil
+	}
+
+	res := make([]AlternateLink, 0, len(cluster.Alternates))
+	for _, a := range cluster.Alternates {
+		href, err := encodeLoc(a.URL)
+		if err != nil {
+			log.Printf("ERR: alternate [%v] of page [%v] is skipped: %v", a.URL, page.URL, err)
+			continue
+		}
+
+		res = append(res, AlternateLink{
+			Rel:      RelAlternate,
+			Hreflang: a.Lang,
+			Href:     href,
+		})
+	}
+
+	return res
+}
@@ -0,0 +1,83 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestReporter_SaveHreflang(t *testing.T) {
+	t.Parallel()
+
+	cluster := &core.AlternateCluster{
+		Alternates: []core.Alternate{
+			{Lang: "de", URL: "http://e.com/de"},
+			{Lang: "en", URL: "http://e.com/en"},
+			{Lang: "x-default", URL: "http://e.com"},
+		},
+	}
+
+	single := &core.AlternateCluster{
+		Alternates: []core.Alternate{{Lang: "fr", URL: "http://e.com/fr"}},
+	}
+
+	src := &core.PageItem{
+		URL:     "http://e.com",
+		Cluster: cluster,
+		Children: []*core.PageItem{
+			{URL: "http://e.com/en", Cluster: cluster},
+			{URL: "http://e.com/de", Cluster: cluster},
+			{URL: "http://e.com/de/conflict", Cluster: cluster},
+			{URL: "http://e.com/fr", Cluster: single},
+			{URL: "http://e.com/about"},
+		},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.xml")
+
+	require.NoError(t, New(Config{FileName: fileName, Hreflang: true}).Save(src))
+
+	buf := readFile(t, fileName)
+	require.Contains(t, string(buf), `xmlns:xhtml="`+XhtmlXmlns+`"`)
+
+	type link struct {
+		Rel      string `xml:"rel,attr"`
+		Hreflang string `xml:"hreflang,attr"`
+		Href     string `xml:"href,attr"`
+	}
+
+	var us struct {
+		URLs []struct {
+			Loc   string `xml:"loc"`
+			Links []link `xml:"http://www.w3.org/1999/xhtml link"`
+		} `xml:"url"`
+	}
+
+	require.NoError(t, xml.Unmarshal(buf, &us))
+
+	expLinks := []link{
+		{Rel: "alternate", Hreflang: "de", Href: "http://e.com/de"},
+		{Rel: "alternate", Hreflang: "en", Href: "http://e.com/en"},
+		{Rel: "alternate", Hreflang: "x-default", Href: "http://e.com"},
+	}
+
+	links := make(map[string][]link)
+	for _, u := range us.URLs {
+		links[u.Loc] = u.Links
+	}
+
+	require.Equal(t, map[string][]link{
+		"http://e.com":             expLinks,
+		"http://e.com/en":          expLinks,
+		"http://e.com/de":          expLinks,
+		"http://e.com/de/conflict": nil,
+		"http://e.com/fr":          nil,
+		"http://e.com/about":       nil,
+	}, links)
+
+	require.NoError(t, New(Config{FileName: fileName}).Save(src))
+	require.NotContains(t, string(readFile(t, fileName)), "xhtml")
+}
@@ -0,0 +1,84 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// htmlTemplate renders a site tree as nested lists.
+var htmlTemplate = template.Must(template.New("sitemap").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Site map of {{.URL}}</title>
+<style>
+body { font-family: sans-serif; }
+ul { list-style: none; padding-left: 1.5em; }
+.excluded, .excluded a { color: #999; }
+</style>
+</head>
+<body>
+<h1>Site map of {{.URL}}</h1>
+<ul>
+{{template "page" .}}
+</ul>
+</body>
+</html>
+{{define "page"}}<li{{if .ExcludeReason}} class="excluded"{{end}}><a href="{{.URL}}">{{.URL}}</a>{{if .ExcludeReason}} ({{.ExcludeReason}}){{end}}
+{{- if .Children}}
+<ul>
+{{range .Children}}{{template "page" .}}
+{{end}}</ul>
+{{- end}}</li>{{end}}
+`))
+
+type (
+	// HTMLWriter saves a browsable HTML site tree. Excluded pages are shown with a reason.
+	HTMLWriter struct {
+		config Config
+	}
+
+	htmlPage struct {
+		URL           string
+		ExcludeReason string
+		Children      []*htmlPage
+	}
+)
+
+func NewHTMLWriter(config Config) *HTMLWriter {
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
+	return &HTMLWriter{
+		config: config,
+	}
+}
+
+func (w *HTMLWriter) Save(tree *core.PageItem) error {
+
+	pages := make(map[*core.PageItem]*htmlPage)
+
+	walkTree(tree, func(page, parent *core.PageItem) {
+
+		p := &htmlPage{
+			URL:           page.URL,
+			ExcludeReason: page.ExcludeReason,
+		}
+
+		if parent != nil {
+			pages[parent].Children = append(pages[parent].Children, p)
+		}
+
+		pages[page] = p
+	})
+
+	var buf bytes.Buffer
+
+	if err := htmlTemplate.Execute(&buf, pages[tree]); err != nil {
+		return fmt.Errorf("failed to render site tree: %w", err)
+	}
+
+	return writeFile(w.config.FileName, buf.Bytes(), w.config.Compress)
+}
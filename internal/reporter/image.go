@@ -0,0 +1,44 @@
+package reporter
+
+import (
+	"log"
+)
+
+const (
+	// ImageXmlns is a namespace of image sitemap extension.
+	ImageXmlns = "http://www.google.com/schemas/sitemap-image/1.1"
+
+	// MaxImages is a max number of images of a single URL according to the image sitemap extension.
+	MaxImages = 1_000
+)
+
+// ImageItem is an <image:image> entry of a URL.
+type ImageItem struct {
+	Loc string `xml:"image:loc"`
+}
+
+// buildImages returns image entries of a page limited by MaxImages.
+func buildImages(pageURL string, images []string) []ImageItem {
+
+	if len(images) == 0 {
+		return nil
+	}
+
+	if len(images) > MaxImages {
+		log.Printf("ERR: page [%v] has %v images, only first %v are saved", pageURL, len(images), MaxImages)
+		images = images[:MaxImages]
+	}
+
+	res := make([]ImageItem, 0, len(images))
+	for _, image := range images {
+		loc, err := encodeLoc(image)
+		if err != nil {
+			log.Printf("ERR: image [%v] of page [%v] is skipped: %v", image, pageURL, err)
+			continue
+		}
+
+		res = append(res, ImageItem{Loc: loc})
+	}
+
+	return res
+}
@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// imageURLSet is a urlset with image entries as they are parsed by a namespace-aware XML parser.
+type imageURLSet struct {
+	URLs []struct {
+		Loc    string `xml:"loc"`
+		Images []struct {
+			Loc string `xml:"loc"`
+		} `xml:"http://www.google.com/schemas/sitemap-image/1.1 image"`
+	} `xml:"url"`
+}
+
+func TestReporter_SaveImages(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		images    bool
+		expImages map[string][]string
+		expNS     bool
+	}
+
+	manyImages := make([]string, 0, MaxImages+1)
+	for i := 0; i <= MaxImages; i++ {
+		manyImages = append(manyImages, fmt.Sprintf("http://e.com/img-%d.png", i))
+	}
+
+	src := &core.PageItem{
+		URL:  "http://e.com",
+		Meta: core.PageMeta{Images: []string{"http://e.com/logo.png", "http://cdn.e.com/a.jpg?w=1&h=2"}},
+		Children: []*core.PageItem{
+			{URL: "http://e.com/gallery", Meta: core.PageMeta{Images: manyImages}},
+			{URL: "http://e.com/text"},
+		},
+	}
+
+	tests := map[string]Test{
+		"enabled": {
+			images: true,
+			expImages: map[string][]string{
+				"http://e.com":         {"http://e.com/logo.png", "http://cdn.e.com/a.jpg?w=1&h=2"},
+				"http://e.com/gallery": manyImages[:MaxImages],
+				"http://e.com/text":    nil,
+			},
+			expNS: true,
+		},
+
+		"disabled": {
+			images: false,
+			expImages: map[string][]string{
+				"http://e.com":         nil,
+				"http://e.com/gallery": nil,
+				"http://e.com/text":    nil,
+			},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			fileName := filepath.Join(t.TempDir(), "sitemap.xml")
+
+			require.NoError(t, New(Config{FileName: fileName, Images: test.images}).Save(src))
+
+			buf := readFile(t, fileName)
+			require.Equal(t, test.expNS, strings.Contains(string(buf), `xmlns:image="`+ImageXmlns+`"`))
+
+			var us imageURLSet
+			require.NoError(t, xml.Unmarshal(buf, &us))
+
+			images := make(map[string][]string)
+			for _, u := range us.URLs {
+				var locs []string
+				for _, image := range u.Images {
+					locs = append(locs, image.Loc)
+				}
+
+				images[u.Loc] = locs
+			}
+
+			require.Equal(t, test.expImages, images)
+		})
+	}
+}
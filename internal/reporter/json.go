@@ -0,0 +1,67 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+type (
+	// JSONWriter saves the whole references tree as a hierarchical JSON document including excluded pages.
+	JSONWriter struct {
+		config Config
+	}
+
+	JSONPage struct {
+		URL           string      `json:"url"`
+		Depth         int         `json:"depth"`
+		Parent        string      `json:"parent,omitempty"`
+		Status        int         `json:"status,omitempty"`
+		LastMod       string      `json:"lastmod,omitempty"`
+		ExcludeReason string      `json:"exclude_reason,omitempty"`
+		Children      []*JSONPage `json:"children,omitempty"`
+	}
+)
+
+func NewJSONWriter(config Config) *JSONWriter {
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
+	return &JSONWriter{
+		config: config,
+	}
+}
+
+func (w *JSONWriter) Save(tree *core.PageItem) error {
+
+	pages := make(map[*core.PageItem]*JSONPage)
+
+	walkTree(tree, func(page, parent *core.PageItem) {
+
+		jsonPage := &JSONPage{
+			URL:           page.URL,
+			Depth:         page.Level,
+			Status:        page.StatusCode,
+			ExcludeReason: page.ExcludeReason,
+		}
+
+		if !page.Meta.LastModified.IsZero() {
+			jsonPage.LastMod = page.Meta.LastModified.UTC().Format(time.RFC3339)
+		}
+
+		if parent != nil {
+			jsonPage.Parent = parent.URL
+			pages[parent].Children = append(pages[parent].Children, jsonPage)
+		}
+
+		pages[page] = jsonPage
+	})
+
+	buf, err := json.MarshalIndent(pages[tree], "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal pages tree: %w", err)
+	}
+
+	return writeFile(w.config.FileName, buf, w.config.Compress)
+}
@@ -0,0 +1,98 @@
+package reporter
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// MaxLocLength is a max length of a URL in a sitemap according to the protocol.
+const MaxLocLength = 2048
+
+// hostProfile converts IDN hosts like idna.Lookup does, but it allows underscores that are common in real host names.
+var hostProfile = idna.New(idna.MapForLookup(), idna.StrictDomainName(false))
+
+// encodeLoc converts a URL to the form the sitemap protocol requires:
+// an IDN host is converted to punycode, and non-ASCII and unsafe characters of a path, a query and a fragment
+// are percent-encoded. Existing percent-encoding is kept. XML entities are not escaped, encoding/xml escapes them.
+// User info is dropped, so credentials don't get into a sitemap.
+// It returns an error if the URL isn't an absolute HTTP URL or it's longer than MaxLocLength.
+func encodeLoc(link string) (string, error) {
+
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", fmt.Errorf("bad URL: %w", err)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return "", fmt.Errorf("not an absolute HTTP URL")
+	}
+
+	host := u.Hostname()
+
+	if net.ParseIP(host) == nil {
+		if host, err = hostProfile.ToASCII(host); err != nil {
+			return "", fmt.Errorf("bad host [%v]: %w", u.Hostname(), err)
+		}
+	}
+
+	if port := u.Port(); len(port) > 0 {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString(strings.ToLower(u.Scheme))
+	sb.WriteString("://")
+
+	sb.WriteString(host)
+	sb.WriteString(escapeUnsafe(u.EscapedPath()))
+
+	if u.ForceQuery || len(u.RawQuery) > 0 {
+		sb.WriteString("?")
+		sb.WriteString(escapeUnsafe(u.RawQuery))
+	}
+
+	if len(u.Fragment) > 0 {
+		sb.WriteString("#")
+		sb.WriteString(escapeUnsafe(u.EscapedFragment()))
+	}
+
+	loc := sb.String()
+
+	if len(loc) > MaxLocLength {
+		return "", fmt.Errorf("URL is longer than %v characters", MaxLocLength)
+	}
+
+	return loc, nil
+}
+
+// escapeUnsafe percent-encodes non-ASCII bytes, control characters and characters that are not allowed in URLs.
+// '%' is kept as is, so encoded parts are not encoded twice.
+func escapeUnsafe(s string) string {
+
+	const hex = "0123456789ABCDEF"
+
+	var sb strings.Builder
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"<>\^`+"`{|}", c) >= 0 {
+			sb.WriteByte('%')
+			sb.WriteByte(hex[c>>4])
+			sb.WriteByte(hex[c&0xf])
+
+			continue
+		}
+
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,233 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+const (
+	// NewsXmlns is a namespace of news sitemap extension.
+	NewsXmlns = "http://www.google.com/schemas/sitemap-news/0.9"
+
+	// MaxNewsURLs is a max number of URLs in a news sitemap.
+	MaxNewsURLs = 1_000
+
+	// DefaultNewsMaxAge is a max age of articles in a news sitemap if NewsConfig.MaxAge isn't set.
+	DefaultNewsMaxAge = 48 * time.Hour
+)
+
+type (
+	// NewsWriter saves a Google News sitemap of recent articles.
+	NewsWriter struct {
+		config NewsConfig
+
+		// now returns the current time that article ages are counted from
+		now func() time.Time
+	}
+
+	NewsConfig struct {
+		FileName string
+
+		// PublicationName and Language override publication name and language of articles.
+		// Articles that have none of them and don't have own ones are skipped.
+		PublicationName string
+		Language        string
+
+		// MaxAge is a max age of articles that are saved. Zero value means DefaultNewsMaxAge.
+		MaxAge time.Duration
+
+		// Compress enables gzip compression of the saved file. GzipExt is added to the file name if it doesn't have it.
+		Compress bool
+	}
+
+	NewsURLSet struct {
+		XMLName   xml.Name  `xml:"urlset"`
+		Xmlns     string    `xml:"xmlns,attr"`
+		XmlnsNews string    `xml:"xmlns:news,attr"`
+		URLSet    []NewsURL `xml:"url"`
+	}
+
+	NewsURL struct {
+		Loc  string   `xml:"loc"`
+		News NewsItem `xml:"news:news"`
+	}
+
+	NewsItem struct {
+		Publication     NewsPublication `xml:"news:publication"`
+		PublicationDate string          `xml:"news:publication_date"`
+		Title           string          `xml:"news:title"`
+	}
+
+	NewsPublication struct {
+		Name     string `xml:"news:name"`
+		Language string `xml:"news:language"`
+	}
+)
+
+func NewNewsWriter(config NewsConfig) *NewsWriter {
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultNewsMaxAge
+	}
+
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
+	return &NewsWriter{
+		config: config,
+		now:    time.Now,
+	}
+}
+
+// Save writes a news sitemap of pages that have article metadata and were published not earlier than MaxAge ago.
+// Pages that have ExcludeReason are not saved. If there are more than MaxNewsURLs articles the most recent ones are saved.
+// Like Reporter.Save it writes articles of the root host to FileName and articles of other hosts
+// to a file with the same name in <FileName dir>/<host>/ directory.
+func (w *NewsWriter) Save(tree *core.PageItem) error {
+
+	minTime := w.now().Add(-w.config.MaxAge)
+
+	rootHost := hostOf(tree.URL)
+
+	hosts := []string{rootHost}
+	hostArticles := map[string][]*core.PageItem{rootHost: nil}
+
+	for _, page := range treeToList(tree) {
+
+		article := page.Meta.Article
+
+		if len(page.ExcludeReason) > 0 || article == nil || article.PublishedTime.Before(minTime) {
+			continue
+		}
+
+		host := hostOf(page.URL)
+		if _, ok := hostArticles[host]; !ok {
+			hosts = append(hosts, host)
+		}
+
+		hostArticles[host] = append(hostArticles[host], page)
+	}
+
+	if err := w.saveNews(w.config.FileName, hostArticles[rootHost]); err != nil {
+		return err
+	}
+
+	for _, host := range hosts[1:] {
+
+		fileName, err := hostFileName(w.config.FileName, host)
+		if err != nil {
+			return err
+		}
+
+		if err := w.saveNews(fileName, hostArticles[host]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveNews writes a news sitemap of articles of a single host to the file.
+func (w *NewsWriter) saveNews(fileName string, articles []*core.PageItem) error {
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].Meta.Article.PublishedTime.After(articles[j].Meta.Article.PublishedTime)
+	})
+
+	urlSet := NewsURLSet{
+		Xmlns:     Xmlns,
+		XmlnsNews: NewsXmlns,
+	}
+
+	for _, page := range articles {
+
+		if len(urlSet.URLSet) >= MaxNewsURLs {
+			log.Printf("ERR: there are more than %v recent articles, older ones are not saved to news sitemap", MaxNewsURLs)
+			break
+		}
+
+		item, err := w.buildNewsItem(page.Meta.Article)
+		if err != nil {
+			log.Printf("ERR: article [%v] is not saved to news sitemap: %v", page.URL, err)
+			continue
+		}
+
+		loc, err := encodeLoc(page.URL)
+		if err != nil {
+			log.Printf("ERR: article [%v] is not saved to news sitemap: %v", page.URL, err)
+			continue
+		}
+
+		urlSet.URLSet = append(urlSet.URLSet, NewsURL{
+			Loc:  loc,
+			News: item,
+		})
+	}
+
+	buf, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal news sitemap: %w", err)
+	}
+
+	return writeFile(fileName, append([]byte(xml.Header), buf...), w.config.Compress)
+}
+
+// buildNewsItem returns a news entry of an article. It returns an error if the article misses required fields.
+func (w *NewsWriter) buildNewsItem(article *core.Article) (NewsItem, error) {
+
+	name := firstNonEmpty(w.config.PublicationName, article.PublicationName)
+	language := newsLanguage(firstNonEmpty(w.config.Language, article.Language))
+
+	switch {
+	case len(name) == 0:
+		return NewsItem{}, fmt.Errorf("no publication name")
+
+	case len(language) == 0:
+		return NewsItem{}, fmt.Errorf("no language")
+
+	case len(article.Title) == 0:
+		return NewsItem{}, fmt.Errorf("no title")
+	}
+
+	return NewsItem{
+		Publication: NewsPublication{
+			Name:     name,
+			Language: language,
+		},
+		PublicationDate: article.PublishedTime.Format(time.RFC3339),
+		Title:           article.Title,
+	}, nil
+}
+
+// newsLanguage converts a language tag to ISO 639 code that news sitemaps accept, e.g. "en-US" to "en".
+// Chinese languages keep their script as zh-cn and zh-tw.
+func newsLanguage(lang string) string {
+
+	lang = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+
+	switch lang {
+	case "zh-cn", "zh-hans", "zh-sg":
+		return "zh-cn"
+
+	case "zh-tw", "zh-hant", "zh-hk":
+		return "zh-tw"
+	}
+
+	code, _, _ := strings.Cut(lang, "-")
+
+	return code
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if len(v) > 0 {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,207 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// newsURLSet is a news sitemap as it's parsed by a namespace-aware XML parser.
+type newsURLSet struct {
+	URLs []struct {
+		Loc  string `xml:"loc"`
+		News struct {
+			Name            string `xml:"publication>name"`
+			Language        string `xml:"publication>language"`
+			PublicationDate string `xml:"publication_date"`
+			Title           string `xml:"title"`
+		} `xml:"http://www.google.com/schemas/sitemap-news/0.9 news"`
+	} `xml:"url"`
+}
+
+func TestNewsWriter_Save(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2022, 5, 6, 12, 0, 0, 0, time.UTC)
+
+	article := func(age time.Duration, title, lang, name string) core.PageMeta {
+		return core.PageMeta{Article: &core.Article{
+			PublishedTime:   now.Add(-age),
+			Title:           title,
+			Language:        lang,
+			PublicationName: name,
+		}}
+	}
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/news/old", Meta: article(49*time.Hour, "Old", "en", "E News")},
+			{URL: "http://e.com/news/recent", Meta: article(47*time.Hour, "Recent", "en-US", "E News")},
+			{URL: "http://e.com/news/fresh", Meta: article(time.Hour, "Fresh & hot", "zh-Hant", "")},
+			{URL: "http://e.com/news/excluded", Meta: article(time.Hour, "Excluded", "en", "E News"), ExcludeReason: "noindex"},
+			{URL: "http://e.com/news/untitled", Meta: article(time.Hour, "", "en", "E News")},
+			{URL: "http://e.com/about"},
+		},
+	}
+
+	type expURL struct {
+		loc, name, lang, date, title string
+	}
+
+	type Test struct {
+		config  NewsConfig
+		expURLs []expURL
+	}
+
+	tests := map[string]Test{
+		"article metadata": {
+			expURLs: []expURL{
+				{"http://e.com/news/recent", "E News", "en", "2022-05-04T13:00:00Z", "Recent"},
+			},
+		},
+
+		"publication overrides": {
+			config: NewsConfig{PublicationName: "Newsroom", Language: "de"},
+			expURLs: []expURL{
+				{"http://e.com/news/fresh", "Newsroom", "de", "2022-05-06T11:00:00Z", "Fresh & hot"},
+				{"http://e.com/news/recent", "Newsroom", "de", "2022-05-04T13:00:00Z", "Recent"},
+			},
+		},
+
+		"publication name only": {
+			config: NewsConfig{PublicationName: "Newsroom", MaxAge: 2 * time.Hour},
+			expURLs: []expURL{
+				{"http://e.com/news/fresh", "Newsroom", "zh-tw", "2022-05-06T11:00:00Z", "Fresh & hot"},
+			},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.FileName = filepath.Join(t.TempDir(), "news.xml")
+
+			w := NewNewsWriter(test.config)
+			w.now = func() time.Time { return now }
+
+			require.NoError(t, w.Save(src))
+
+			var us newsURLSet
+			require.NoError(t, xml.Unmarshal(readFile(t, test.config.FileName), &us))
+
+			urls := make([]expURL, 0, len(us.URLs))
+			for _, u := range us.URLs {
+				urls = append(urls, expURL{u.Loc, u.News.Name, u.News.Language, u.News.PublicationDate, u.News.Title})
+			}
+
+			require.Equal(t, test.expURLs, urls)
+		})
+	}
+}
+
+func TestNewsWriter_SaveLimit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	src := &core.PageItem{URL: "http://e.com"}
+	for i := 0; i <= MaxNewsURLs; i++ {
+		src.Children = append(src.Children, &core.PageItem{
+			URL: fmt.Sprintf("http://e.com/news/%d", i),
+			Meta: core.PageMeta{Article: &core.Article{
+				PublishedTime:   now.Add(-time.Duration(i) * time.Minute),
+				Title:           "Article",
+				PublicationName: "E News",
+				Language:        "en",
+			}},
+		})
+	}
+
+	fileName := filepath.Join(t.TempDir(), "news.xml")
+
+	require.NoError(t, NewNewsWriter(NewsConfig{FileName: fileName, Compress: true}).Save(src))
+
+	var us newsURLSet
+	require.NoError(t, xml.Unmarshal(readFile(t, fileName+GzipExt), &us))
+
+	require.Len(t, us.URLs, MaxNewsURLs)
+	require.Equal(t, "http://e.com/news/0", us.URLs[0].Loc)
+	require.Equal(t, fmt.Sprintf("http://e.com/news/%d", MaxNewsURLs-1), us.URLs[MaxNewsURLs-1].Loc)
+}
+
+func TestNewsWriter_SaveMultiHost(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	article := core.PageMeta{Article: &core.Article{
+		PublishedTime:   now.Add(-time.Hour),
+		Title:           "Article",
+		PublicationName: "E News",
+		Language:        "en",
+	}}
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/news/1", Meta: article},
+			{URL: "https://blog.e.com/news/2", Meta: article},
+			{URL: "https://blog.e.com/about"},
+			{URL: "http://www.e.com/about"},
+		},
+	}
+
+	dir := t.TempDir()
+
+	require.NoError(t, NewNewsWriter(NewsConfig{FileName: filepath.Join(dir, "news.xml")}).Save(src))
+
+	locs := func(fileName string) []string {
+		var us newsURLSet
+		require.NoError(t, xml.Unmarshal(readFile(t, fileName), &us))
+
+		res := make([]string, 0, len(us.URLs))
+		for _, u := range us.URLs {
+			res = append(res, u.Loc)
+		}
+
+		return res
+	}
+
+	require.Equal(t, []string{"http://e.com/news/1"}, locs(filepath.Join(dir, "news.xml")))
+	require.Equal(t, []string{"https://blog.e.com/news/2"}, locs(filepath.Join(dir, "blog.e.com", "news.xml")))
+	require.NoDirExists(t, filepath.Join(dir, "www.e.com"))
+}
+
+func TestNewsWriter_newsLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"en":      "en",
+		"en-US":   "en",
+		"pt_BR":   "pt",
+		"zh-CN":   "zh-cn",
+		"zh-Hant": "zh-tw",
+		"":        "",
+	}
+
+	//nolint:paralleltest
+	for lang, expLang := range tests {
+		lang, expLang := lang, expLang
+
+		t.Run(lang, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, expLang, newsLanguage(lang))
+		})
+	}
+}
@@ -1,15 +1,35 @@
 package reporter
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
 )
 
-const Xlmns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+const (
+	Xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+	// MaxURLs is a max number of URLs in a single sitemap file according to the protocol.
+	MaxURLs = 50_000
+
+	// MaxFileSize is a max size of uncompressed sitemap file according to the protocol.
+	MaxFileSize = 50 * 1024 * 1024
+
+	// GzipExt is an extension of compressed sitemap files.
+	GzipExt = ".gz"
+
+	urlSetClose = `</urlset>`
+)
 
 type (
 	Reporter struct {
@@ -18,88 +38,397 @@ type (
 
 	Config struct {
 		FileName string
+
+		// BaseURL is a URL where sitemap files are published.
+		// It's used to build child sitemaps locations in a sitemap index.
+		BaseURL string
+
+		// MaxURLs and MaxFileSize limit a single sitemap file.
+		// When a sitemap exceeds any of them it's split into several files and a sitemap index is saved to FileName.
+		// Zero values mean protocol limits.
+		MaxURLs     int
+		MaxFileSize int
+
+		// Compress enables gzip compression of all saved files.
+		// GzipExt is added to file names that don't have it.
+		// MaxFileSize is still applied to uncompressed data as the protocol requires.
+		Compress bool
+
+		// Rules set <changefreq> and <priority> of URLs.
+		// Priority of URLs that have no matching rule is derived from the page depth.
+		Rules *Rules
+
+		// Images enables <image:image> entries of page images.
+		Images bool
+
+		// Videos enables <video:video> entries of page videos. Videos that miss required fields are skipped.
+		Videos bool
+
+		// Hreflang enables <xhtml:link rel="alternate"> entries of all page alternates of the page hreflang cluster.
+		Hreflang bool
 	}
 
 	URLSet struct {
 		XMLName xml.Name  `xml:"urlset"`
 		URLSet  []URLItem `xml:"url"`
-		Xmlns   string    `xml:"xlmns,attr"`
+		Xmlns   string    `xml:"xmlns,attr"`
 	}
 
 	URLItem struct {
+		XMLName    xml.Name `xml:"url"`
+		Loc        string   `xml:"loc"`
+		LastMod    string   `xml:"lastmod,omitempty"`
+		ChangeFreq string   `xml:"changefreq,omitempty"`
+		Priority   string   `xml:"priority,omitempty"`
+
+		Images []ImageItem `xml:"image:image,omitempty"`
+		Videos []VideoItem `xml:"video:video,omitempty"`
+
+		Alternates []AlternateLink `xml:"xhtml:link,omitempty"`
+	}
+
+	SitemapIndex struct {
+		XMLName  xml.Name      `xml:"sitemapindex"`
+		Sitemaps []SitemapItem `xml:"sitemap"`
+		Xmlns    string        `xml:"xmlns,attr"`
+	}
+
+	SitemapItem struct {
 		Loc string `xml:"loc"`
 	}
+
+	// Multi saves a tree with all reporters one by one. It stops on the first error.
+	Multi []core.Reporter
 )
 
 func New(config Config) *Reporter {
+	if config.MaxURLs <= 0 {
+		config.MaxURLs = MaxURLs
+	}
+
+	if config.MaxFileSize <= 0 {
+		config.MaxFileSize = MaxFileSize
+	}
+
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
 	return &Reporter{
 		config: config,
 	}
 }
 
+// Save writes a sitemap to the FileName.
+// If the sitemap doesn't fit into protocol limits it's split into sitemap-1.xml, sitemap-2.xml, ... files
+// that are saved next to FileName, and FileName gets a sitemap index that refers them.
+// With Compress option all files are gzipped and get .gz extension.
+// Pages that have ExcludeReason are not saved.
+//
+// A sitemap may contain URLs of a single host only, so URLs of hosts other than the root page host
+// are saved to separate sitemaps in <FileName dir>/<host>/ directories. BaseURL of such sitemaps is the host root.
 func (r *Reporter) Save(tree *core.PageItem) error {
 
-	links := treeToList(tree)
+	pages := treeToList(tree)
 
-	us := URLSet{
-		URLSet: make([]URLItem, 0, len(links)),
-		Xmlns:  Xlmns,
-	}
+	rootHost := hostOf(tree.URL)
 
-	for _, link := range links {
+	hosts := []string{rootHost}
+	hostItems := map[string][]URLItem{rootHost: nil}
+	hostBases := make(map[string]string)
 
-		u := escapeLink(link)
+	for _, page := range pages {
+
+		if len(page.ExcludeReason) > 0 {
+			continue
+		}
+
+		loc, err := encodeLoc(page.URL)
+		if err != nil {
+			log.Printf("ERR: page [%v] is not saved: %v", page.URL, err)
+			continue
+		}
 
 		urlItem := URLItem{
-			Loc: u,
+			Loc: loc,
+		}
+
+		if !page.Meta.LastModified.IsZero() {
+			urlItem.LastMod = page.Meta.LastModified.UTC().Format(time.RFC3339)
+		}
+
+		r.config.Rules.apply(&urlItem, page)
+
+		if r.config.Images {
+			urlItem.Images = buildImages(page.URL, page.Meta.Images)
+		}
+
+		if r.config.Videos {
+			urlItem.Videos = buildVideos(page.URL, page.Meta.Videos)
+		}
+
+		if r.config.Hreflang {
+			urlItem.Alternates = buildAlternates(page)
+		}
+
+		host := hostOf(page.URL)
+		if _, ok := hostItems[host]; !ok {
+			hosts = append(hosts, host)
+			hostBases[host] = rootOf(page.URL)
+		}
+
+		hostItems[host] = append(hostItems[host], urlItem)
+	}
+
+	if err := r.saveSitemap(r.config.FileName, r.config.BaseURL, hostItems[rootHost]); err != nil {
+		return err
+	}
+
+	for _, host := range hosts[1:] {
+
+		fileName, err := hostFileName(r.config.FileName, host)
+		if err != nil {
+			return err
 		}
 
-		us.URLSet = append(us.URLSet, urlItem)
+		if err := r.saveSitemap(fileName, hostBases[host], hostItems[host]); err != nil {
+			return err
+		}
 	}
 
-	buf, err := xml.MarshalIndent(us, "", "  ")
+	return nil
+}
+
+// hostFileName returns a path of a file with the same name in <file dir>/<host>/ directory and creates the directory.
+// A sitemap may contain URLs of a single host only, so URLs of other hosts than the root one are saved there.
+func hostFileName(fileName, host string) (string, error) {
+
+	dir := filepath.Join(filepath.Dir(fileName), strings.ReplaceAll(host, ":", "_"))
+
+	//nolint:gosec
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory [%v]: %w", dir, err)
+	}
+
+	return filepath.Join(dir, filepath.Base(fileName)), nil
+}
+
+func (m Multi) Save(tree *core.PageItem) error {
+	for _, r := range m {
+		if err := r.Save(tree); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// saveSitemap writes URL items of a single host to the file splitting them into shards if it's required.
+func (r *Reporter) saveSitemap(fileName, baseURL string, items []URLItem) error {
+
+	urlSetOpen := buildURLSetOpen(items)
+
+	shards, err := r.splitItems(items, len(urlSetOpen))
 	if err != nil {
-		return fmt.Errorf("failed to marshal report: %w", err)
+		return err
+	}
+
+	if len(shards) == 1 {
+		return r.writeFile(fileName, buildURLSet(urlSetOpen, shards[0]))
+	}
+
+	index := SitemapIndex{
+		Sitemaps: make([]SitemapItem, 0, len(shards)),
+		Xmlns:    Xmlns,
 	}
 
-	resBuffer := append([]byte(xml.Header), buf...)
+	dir := filepath.Dir(fileName)
+
+	for i, shard := range shards {
+		shardName := fmt.Sprintf("sitemap-%d.xml", i+1)
+		if r.config.Compress {
+			shardName += GzipExt
+		}
+
+		if err := r.writeFile(filepath.Join(dir, shardName), buildURLSet(urlSetOpen, shard)); err != nil {
+			return err
+		}
+
+		loc := joinURL(baseURL, shardName)
+
+		// a relative location is kept if BaseURL isn't set
+		if len(baseURL) > 0 {
+			if loc, err = encodeLoc(loc); err != nil {
+				return fmt.Errorf("bad sitemap location [%v]: %w", joinURL(baseURL, shardName), err)
+			}
+		}
+
+		index.Sitemaps = append(index.Sitemaps, SitemapItem{
+			Loc: loc,
+		})
+	}
+
+	buf, err := xml.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal sitemap index: %w", err)
+	}
+
+	return r.writeFile(fileName, append([]byte(xml.Header), buf...))
+}
+
+// splitItems marshals URL items and groups them into shards so every shard fits into MaxURLs and MaxFileSize limits.
+// urlSetOpenSize is a size of an opening <urlset> tag of shards.
+func (r *Reporter) splitItems(items []URLItem, urlSetOpenSize int) ([][][]byte, error) {
+
+	overhead := len(xml.Header) + urlSetOpenSize + len("\n") + len(urlSetClose)
+
+	shards := [][][]byte{nil}
+	shardSize := overhead
+
+	for _, item := range items {
+
+		buf, err := xml.MarshalIndent(item, "  ", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal url [%v]: %w", item.Loc, err)
+		}
+
+		itemSize := len(buf) + len("\n")
+
+		if overhead+itemSize > r.config.MaxFileSize {
+			return nil, fmt.Errorf("url [%v] doesn't fit into sitemap file size limit", item.Loc)
+		}
+
+		cur := shards[len(shards)-1]
+
+		if len(cur) >= r.config.MaxURLs || shardSize+itemSize > r.config.MaxFileSize {
+			shards = append(shards, nil)
+			shardSize = overhead
+		}
+
+		shards[len(shards)-1] = append(shards[len(shards)-1], buf)
+		shardSize += itemSize
+	}
+
+	return shards, nil
+}
+
+// buildURLSetOpen returns an opening <urlset> tag that declares the sitemap namespace
+// and namespaces of extensions that URL items use.
+func buildURLSetOpen(items []URLItem) string {
+
+	var hasImages, hasVideos, hasAlternates bool
+
+	for _, item := range items {
+		hasImages = hasImages || len(item.Images) > 0
+		hasVideos = hasVideos || len(item.Videos) > 0
+		hasAlternates = hasAlternates || len(item.Alternates) > 0
+	}
+
+	urlSetOpen := `<urlset xmlns="` + Xmlns + `"`
+
+	if hasImages {
+		urlSetOpen += ` xmlns:image="` + ImageXmlns + `"`
+	}
+
+	if hasVideos {
+		urlSetOpen += ` xmlns:video="` + VideoXmlns + `"`
+	}
+
+	if hasAlternates {
+		urlSetOpen += ` xmlns:xhtml="` + XhtmlXmlns + `"`
+	}
+
+	return urlSetOpen + `>`
+}
+
+// buildURLSet assembles a urlset document from marshaled URL items.
+// The result is the same as xml.MarshalIndent() of URLSet would produce.
+func buildURLSet(urlSetOpen string, items [][]byte) []byte {
+
+	var sb strings.Builder
+
+	sb.WriteString(xml.Header)
+	sb.WriteString(urlSetOpen)
+
+	for _, item := range items {
+		sb.WriteString("\n")
+		sb.Write(item)
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(urlSetClose)
+
+	return []byte(sb.String())
+}
+
+// writeFile saves data to a file compressing it if it's required by config.
+func (r *Reporter) writeFile(fileName string, buf []byte) error {
+	return writeFile(fileName, buf, r.config.Compress)
+}
+
+// writeFile saves data to a file gzipping it if compress is true.
+func writeFile(fileName string, buf []byte, compress bool) error {
+
+	if compress {
+		var gzBuf bytes.Buffer
+
+		zw := gzip.NewWriter(&gzBuf)
+
+		if _, err := zw.Write(buf); err != nil {
+			return fmt.Errorf("failed to compress file [%v]: %w", fileName, err)
+		}
+
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("failed to compress file [%v]: %w", fileName, err)
+		}
+
+		buf = gzBuf.Bytes()
+	}
 
 	//nolint:gosec
-	if err := ioutil.WriteFile(r.config.FileName, resBuffer, 0644); err != nil {
-		return fmt.Errorf("failed to save file: %w", err)
+	if err := ioutil.WriteFile(fileName, buf, 0644); err != nil {
+		return fmt.Errorf("failed to save file [%v]: %w", fileName, err)
 	}
 
 	return nil
 }
 
-func escapeLink(link string) string {
+func joinURL(base, name string) string {
+	if len(base) == 0 {
+		return name
+	}
+
+	return strings.TrimSuffix(base, "/") + "/" + name
+}
 
-	escapeSymbols := map[string]string{
-		`&`: "&amp;",
-		`'`: "&apos;",
-		`\`: "&quot;",
-		`>`: "&gt;",
-		`<`: "&lt;",
+// hostOf returns a lowercased host (with port) of the URL or an empty string if the URL is invalid.
+func hostOf(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
 	}
 
-	escapedLink := link
-	for k, v := range escapeSymbols {
-		escapedLink = strings.ReplaceAll(escapedLink, k, v)
+	return strings.ToLower(u.Host)
+}
+
+// rootOf returns scheme and host of the URL.
+func rootOf(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
 	}
 
-	return escapedLink
+	return u.Scheme + "://" + u.Host
 }
 
-func treeToList(root *core.PageItem) []string {
+func treeToList(root *core.PageItem) []*core.PageItem {
 
-	var addBranch func(lst []string, root *core.PageItem) []string
-	addBranch = func(lst []string, root *core.PageItem) []string {
+	var addBranch func(lst []*core.PageItem, root *core.PageItem) []*core.PageItem
+	addBranch = func(lst []*core.PageItem, root *core.PageItem) []*core.PageItem {
 
 		for _, child := range root.Children {
 			lst = addBranch(lst, child)
 		}
 
-		return append(lst, root.URL)
+		return append(lst, root)
 	}
 
 	return addBranch(nil, root)
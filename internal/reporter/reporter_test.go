@@ -1,7 +1,16 @@
 package reporter
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
@@ -41,9 +50,298 @@ func TestReporter_treeToList(t *testing.T) {
 
 	resMap := make(map[string]interface{}, len(resSlice))
 	for _, r := range resSlice {
-		resMap[r] = nil
+		resMap[r.URL] = nil
 	}
 
 	require.Equal(t, expRes, resMap)
 
 }
+
+func TestReporter_Save(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		maxURLs     int
+		maxFileSize int
+		expShards   [][]string
+		expErr      bool
+	}
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/link1"},
+			{URL: "http://e.com/link2"},
+			{URL: "http://e.com/link3"},
+			{URL: "http://e.com/link4"},
+		},
+	}
+
+	tests := map[string]Test{
+		"Single file": {
+			expShards: [][]string{
+				{"http://e.com/link1", "http://e.com/link2", "http://e.com/link3", "http://e.com/link4", "http://e.com"},
+			},
+		},
+
+		"Split by URLs number": {
+			maxURLs: 2,
+			expShards: [][]string{
+				{"http://e.com/link1", "http://e.com/link2"},
+				{"http://e.com/link3", "http://e.com/link4"},
+				{"http://e.com"},
+			},
+		},
+
+		"Split by file size": {
+			maxFileSize: 300,
+			expShards: [][]string{
+				{"http://e.com/link1", "http://e.com/link2"},
+				{"http://e.com/link3", "http://e.com/link4"},
+				{"http://e.com"},
+			},
+		},
+
+		"URL exceeds file size": {
+			maxFileSize: 100,
+			expErr:      true,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			fileName := filepath.Join(dir, "sitemap.xml")
+
+			r := New(Config{
+				FileName:    fileName,
+				BaseURL:     "http://e.com/",
+				MaxURLs:     test.maxURLs,
+				MaxFileSize: test.maxFileSize,
+			})
+
+			err := r.Save(src)
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			if len(test.expShards) == 1 {
+				require.Equal(t, test.expShards[0], readURLSet(t, fileName))
+				return
+			}
+
+			buf := readFile(t, fileName)
+
+			var index SitemapIndex
+			require.NoError(t, xml.Unmarshal(buf, &index))
+			require.Equal(t, Xmlns, index.Xmlns)
+			require.Len(t, index.Sitemaps, len(test.expShards))
+
+			for i, shard := range test.expShards {
+				shardName := fmt.Sprintf("sitemap-%d.xml", i+1)
+				require.Equal(t, "http://e.com/"+shardName, index.Sitemaps[i].Loc)
+
+				shardFile := filepath.Join(dir, shardName)
+				require.Equal(t, shard, readURLSet(t, shardFile))
+
+				if test.maxFileSize > 0 {
+					info, err := os.Stat(shardFile)
+					require.NoError(t, err)
+					require.LessOrEqual(t, info.Size(), int64(test.maxFileSize))
+				}
+			}
+		})
+	}
+}
+
+func TestReporter_SaveLastMod(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Meta: core.PageMeta{
+			LastModified: time.Date(2022, 5, 6, 10, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+		},
+		Children: []*core.PageItem{
+			{URL: "http://e.com/link1", Level: 1},
+		},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.xml")
+
+	r := New(Config{
+		FileName: fileName,
+	})
+
+	require.NoError(t, r.Save(src))
+
+	var us URLSet
+	require.NoError(t, xml.Unmarshal(readFile(t, fileName), &us))
+
+	require.Equal(t, []URLItem{
+		{XMLName: xml.Name{Space: Xmlns, Local: "url"}, Loc: "http://e.com/link1", Priority: "0.8"},
+		{XMLName: xml.Name{Space: Xmlns, Local: "url"}, Loc: "http://e.com", LastMod: "2022-05-06T08:00:00Z", Priority: "1.0"},
+	}, us.URLSet)
+}
+
+func TestReporter_SaveExcluded(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/noindex", ExcludeReason: "noindex", Children: []*core.PageItem{
+				{URL: "http://e.com/noindex/link1"},
+			}},
+		},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.xml")
+
+	require.NoError(t, New(Config{FileName: fileName}).Save(src))
+	require.Equal(t, []string{"http://e.com/noindex/link1", "http://e.com"}, readURLSet(t, fileName))
+}
+
+func TestReporter_SaveCompressed(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/link1"},
+			{URL: "http://e.com/link2"},
+		},
+	}
+
+	dir := t.TempDir()
+
+	r := New(Config{
+		FileName: filepath.Join(dir, "sitemap.xml"),
+		BaseURL:  "http://e.com",
+		MaxURLs:  2,
+		Compress: true,
+	})
+
+	require.NoError(t, r.Save(src))
+
+	buf := readFile(t, filepath.Join(dir, "sitemap.xml.gz"))
+
+	var index SitemapIndex
+	require.NoError(t, xml.Unmarshal(buf, &index))
+	require.Equal(t, []SitemapItem{
+		{Loc: "http://e.com/sitemap-1.xml.gz"},
+		{Loc: "http://e.com/sitemap-2.xml.gz"},
+	}, index.Sitemaps)
+
+	require.Equal(t, []string{"http://e.com/link1", "http://e.com/link2"}, readURLSet(t, filepath.Join(dir, "sitemap-1.xml.gz")))
+	require.Equal(t, []string{"http://e.com"}, readURLSet(t, filepath.Join(dir, "sitemap-2.xml.gz")))
+}
+
+func TestReporter_SaveMultiHost(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/link1"},
+			{URL: "https://blog.e.com/post1"},
+			{URL: "http://www.e.com/link2"},
+			{URL: "https://blog.e.com/post2"},
+		},
+	}
+
+	dir := t.TempDir()
+
+	r := New(Config{
+		FileName: filepath.Join(dir, "sitemap.xml"),
+		BaseURL:  "http://e.com",
+		MaxURLs:  1,
+	})
+
+	require.NoError(t, r.Save(src))
+
+	require.Equal(t, []string{"http://e.com/link1"}, readURLSet(t, filepath.Join(dir, "sitemap-1.xml")))
+	require.Equal(t, []string{"http://e.com"}, readURLSet(t, filepath.Join(dir, "sitemap-2.xml")))
+
+	require.Equal(t, []string{"http://www.e.com/link2"}, readURLSet(t, filepath.Join(dir, "www.e.com", "sitemap.xml")))
+
+	var index SitemapIndex
+	require.NoError(t, xml.Unmarshal(readFile(t, filepath.Join(dir, "blog.e.com", "sitemap.xml")), &index))
+	require.Equal(t, []SitemapItem{
+		{Loc: "https://blog.e.com/sitemap-1.xml"},
+		{Loc: "https://blog.e.com/sitemap-2.xml"},
+	}, index.Sitemaps)
+
+	require.Equal(t, []string{"https://blog.e.com/post1"}, readURLSet(t, filepath.Join(dir, "blog.e.com", "sitemap-1.xml")))
+	require.Equal(t, []string{"https://blog.e.com/post2"}, readURLSet(t, filepath.Join(dir, "blog.e.com", "sitemap-2.xml")))
+}
+
+// readFile reads a file and decompresses it if it has GzipExt.
+func readFile(t *testing.T, fileName string) []byte {
+	t.Helper()
+
+	buf, err := ioutil.ReadFile(fileName)
+	require.NoError(t, err)
+
+	if !strings.HasSuffix(fileName, GzipExt) {
+		return buf
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(buf))
+	require.NoError(t, err)
+
+	res, err := ioutil.ReadAll(zr)
+	require.NoError(t, err)
+
+	return res
+}
+
+func readURLSet(t *testing.T, fileName string) []string {
+	t.Helper()
+
+	buf := readFile(t, fileName)
+
+	var us URLSet
+	require.NoError(t, xml.Unmarshal(buf, &us))
+	require.Equal(t, Xmlns, us.Xmlns)
+
+	res := make([]string, 0, len(us.URLSet))
+	for _, item := range us.URLSet {
+		res = append(res, item.Loc)
+	}
+
+	return res
+}
+
+func TestReporter_Multi(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{URL: "http://e.com"}
+
+	dir := t.TempDir()
+
+	m := Multi{
+		New(Config{FileName: filepath.Join(dir, "sitemap.xml")}),
+		NewNewsWriter(NewsConfig{FileName: filepath.Join(dir, "news.xml")}),
+	}
+
+	require.NoError(t, m.Save(src))
+	require.FileExists(t, filepath.Join(dir, "sitemap.xml"))
+	require.FileExists(t, filepath.Join(dir, "news.xml"))
+
+	m = Multi{
+		New(Config{FileName: filepath.Join(dir, "no-dir", "sitemap.xml")}),
+		NewNewsWriter(NewsConfig{FileName: filepath.Join(dir, "news-2.xml")}),
+	}
+
+	require.Error(t, m.Save(src))
+	require.NoFileExists(t, filepath.Join(dir, "news-2.xml"))
+}
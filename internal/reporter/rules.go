@@ -0,0 +1,157 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/pattern"
+)
+
+const (
+	// MaxDepthPriority is a priority of a root page when no rule matches.
+	// Every next level of depth decreases priority by DepthPriorityStep down to MinDepthPriority.
+	MaxDepthPriority  = 1.0
+	DepthPriorityStep = 0.2
+	MinDepthPriority  = 0.1
+)
+
+// changeFreqs are valid <changefreq> values.
+var changeFreqs = map[string]interface{}{
+	"always":  nil,
+	"hourly":  nil,
+	"daily":   nil,
+	"weekly":  nil,
+	"monthly": nil,
+	"yearly":  nil,
+	"never":   nil,
+}
+
+type (
+
+	// Rule sets <changefreq> and <priority> of pages which path and query match the Pattern.
+	// Pattern is a glob or a regular expression with "re:" prefix.
+	// Empty ChangeFreq and nil Priority are not set by the rule.
+	Rule struct {
+		Pattern    string   `json:"pattern"`
+		ChangeFreq string   `json:"changefreq,omitempty"`
+		Priority   *float64 `json:"priority,omitempty"`
+	}
+
+	// Rules is an ordered list of compiled rules. The first matching rule is applied.
+	Rules struct {
+		rules    []Rule
+		patterns []*pattern.Pattern
+	}
+)
+
+// LoadRules reads rules from a JSON file that contains an array of rules.
+func LoadRules(fileName string) (*Rules, error) {
+
+	buf, err := ioutil.ReadFile(fileName) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(buf, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	return NewRules(rules)
+}
+
+// NewRules validates and compiles rules.
+func NewRules(rules []Rule) (*Rules, error) {
+
+	res := Rules{
+		rules:    rules,
+		patterns: make([]*pattern.Pattern, 0, len(rules)),
+	}
+
+	for _, rule := range rules {
+
+		if _, ok := changeFreqs[rule.ChangeFreq]; len(rule.ChangeFreq) > 0 && !ok {
+			return nil, fmt.Errorf("rule [%v] has bad changefreq [%v]", rule.Pattern, rule.ChangeFreq)
+		}
+
+		if rule.Priority != nil && (*rule.Priority < 0 || *rule.Priority > 1) {
+			return nil, fmt.Errorf("rule [%v] has priority [%v] out of [0, 1] range", rule.Pattern, *rule.Priority)
+		}
+
+		p, err := pattern.Compile(rule.Pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		res.patterns = append(res.patterns, p)
+	}
+
+	return &res, nil
+}
+
+// apply sets <changefreq> and <priority> of URL item according to the first matching rule.
+// If rule doesn't set a priority it's derived from the page depth.
+func (rs *Rules) apply(urlItem *URLItem, page *core.PageItem) {
+
+	priority := depthPriority(page.Level)
+
+	if rule := rs.match(page.URL); rule != nil {
+		urlItem.ChangeFreq = rule.ChangeFreq
+
+		if rule.Priority != nil {
+			priority = *rule.Priority
+		}
+	}
+
+	urlItem.Priority = formatPriority(priority)
+}
+
+func (rs *Rules) match(pageURL string) *Rule {
+
+	if rs == nil {
+		return nil
+	}
+
+	u, err := url.Parse(pageURL)
+	if err != nil {
+		return nil
+	}
+
+	target := pattern.PathAndQuery(u)
+
+	for i, p := range rs.patterns {
+		if p.Match(target) {
+			return &rs.rules[i]
+		}
+	}
+
+	return nil
+}
+
+// depthPriority returns a priority of the depth level rounded to one decimal, so float errors don't get to the output.
+func depthPriority(level int) float64 {
+
+	priority := MaxDepthPriority - DepthPriorityStep*float64(level)
+	if priority < MinDepthPriority {
+		return MinDepthPriority
+	}
+
+	return math.Round(priority*10) / 10
+}
+
+// formatPriority formats a priority as it's set, with at least one decimal (e.g. 1.0, 0.85).
+func formatPriority(priority float64) string {
+
+	s := strconv.FormatFloat(priority, 'f', -1, 64)
+	if !strings.Contains(s, ".") {
+		s += ".0"
+	}
+
+	return s
+}
@@ -0,0 +1,106 @@
+package reporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestRules_apply(t *testing.T) {
+	t.Parallel()
+
+	rulesFile := filepath.Join(t.TempDir(), "rules.json")
+
+	require.NoError(t, os.WriteFile(rulesFile, []byte(`[
+		{"pattern": "/blog/tag/**", "changefreq": "weekly", "priority": 0.2},
+		{"pattern": "/blog/**", "changefreq": "daily"},
+		{"pattern": "re:[?&]page=", "priority": 0.1},
+		{"pattern": "/docs/**", "priority": 0.85}
+	]`), 0600))
+
+	rules, err := LoadRules(rulesFile)
+	require.NoError(t, err)
+
+	type Test struct {
+		page    core.PageItem
+		expItem URLItem
+	}
+
+	tests := map[string]Test{
+		"first matching rule": {
+			page:    core.PageItem{URL: "http://e.com/blog/tag/go", Level: 1},
+			expItem: URLItem{ChangeFreq: "weekly", Priority: "0.2"},
+		},
+
+		"priority from depth": {
+			page:    core.PageItem{URL: "http://e.com/blog/post", Level: 2},
+			expItem: URLItem{ChangeFreq: "daily", Priority: "0.6"},
+		},
+
+		"query": {
+			page:    core.PageItem{URL: "http://e.com/list?page=2", Level: 1},
+			expItem: URLItem{Priority: "0.1"},
+		},
+
+		"two-decimal priority": {
+			page:    core.PageItem{URL: "http://e.com/docs/intro", Level: 1},
+			expItem: URLItem{Priority: "0.85"},
+		},
+
+		"rounded depth priority": {
+			page:    core.PageItem{URL: "http://e.com/a/b/c", Level: 3},
+			expItem: URLItem{Priority: "0.4"},
+		},
+
+		"no rule": {
+			page:    core.PageItem{URL: "http://e.com", Level: 0},
+			expItem: URLItem{Priority: "1.0"},
+		},
+
+		"min depth priority": {
+			page:    core.PageItem{URL: "http://e.com/a/b/c/d/e/f", Level: 6},
+			expItem: URLItem{Priority: "0.1"},
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			var item URLItem
+			rules.apply(&item, &test.page)
+
+			require.Equal(t, test.expItem, item)
+		})
+	}
+}
+
+func TestRules_NewRulesErrors(t *testing.T) {
+	t.Parallel()
+
+	badPriority := 1.5
+
+	tests := map[string][]Rule{
+		"bad changefreq": {{Pattern: "/**", ChangeFreq: "sometimes"}},
+		"bad priority":   {{Pattern: "/**", Priority: &badPriority}},
+		"bad pattern":    {{Pattern: "re:[a-"}},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewRules(test)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package reporter
+
+import (
+	"log"
+	"strings"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+// TextWriter saves a plain text sitemap (urllist.txt): a URL per line.
+// URLs are encoded as in XML sitemaps. Pages that have ExcludeReason are not saved.
+type TextWriter struct {
+	config Config
+}
+
+func NewTextWriter(config Config) *TextWriter {
+	config.FileName = gzipFileName(config.FileName, config.Compress)
+
+	return &TextWriter{
+		config: config,
+	}
+}
+
+func (w *TextWriter) Save(tree *core.PageItem) error {
+
+	var sb strings.Builder
+
+	walkTree(tree, func(page, _ *core.PageItem) {
+
+		if len(page.ExcludeReason) > 0 {
+			return
+		}
+
+		loc, err := encodeLoc(page.URL)
+		if err != nil {
+			log.Printf("ERR: page [%v] is not saved: %v", page.URL, err)
+			return
+		}
+
+		sb.WriteString(loc)
+		sb.WriteString("\n")
+	})
+
+	return writeFile(w.config.FileName, []byte(sb.String()), w.config.Compress)
+}
@@ -0,0 +1,126 @@
+package reporter
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+const (
+	// VideoXmlns is a namespace of video sitemap extension.
+	VideoXmlns = "http://www.google.com/schemas/sitemap-video/1.1"
+
+	// MaxVideoDescription is a max length of a video description in characters.
+	MaxVideoDescription = 2048
+
+	// MaxVideoDuration is a max video duration according to the video sitemap extension.
+	MaxVideoDuration = 8 * time.Hour
+)
+
+// VideoItem is a <video:video> entry of a URL.
+type VideoItem struct {
+	ThumbnailLoc string `xml:"video:thumbnail_loc"`
+	Title        string `xml:"video:title"`
+	Description  string `xml:"video:description"`
+	ContentLoc   string `xml:"video:content_loc,omitempty"`
+	PlayerLoc    string `xml:"video:player_loc,omitempty"`
+	Duration     int    `xml:"video:duration,omitempty"`
+}
+
+// buildVideos returns valid video entries of a page. Invalid videos are logged and skipped.
+func buildVideos(pageURL string, videos []core.Video) []VideoItem {
+
+	var res []VideoItem
+
+	for _, video := range videos {
+
+		if err := validateVideo(pageURL, video); err != nil {
+			log.Printf("ERR: video [%v] of page [%v] is skipped: %v", firstLoc(video), pageURL, err)
+			continue
+		}
+
+		locs, err := encodeLocs(video.ThumbnailURL, video.ContentURL, video.PlayerURL)
+		if err != nil {
+			log.Printf("ERR: video [%v] of page [%v] is skipped: %v", firstLoc(video), pageURL, err)
+			continue
+		}
+
+		description := []rune(video.Description)
+		if len(description) > MaxVideoDescription {
+			description = description[:MaxVideoDescription]
+		}
+
+		item := VideoItem{
+			ThumbnailLoc: locs[0],
+			Title:        video.Title,
+			Description:  string(description),
+			ContentLoc:   locs[1],
+			PlayerLoc:    locs[2],
+		}
+
+		if video.Duration >= time.Second && video.Duration <= MaxVideoDuration {
+			item.Duration = int(video.Duration.Round(time.Second).Seconds())
+		}
+
+		res = append(res, item)
+	}
+
+	return res
+}
+
+// validateVideo checks that a video has all fields that the video sitemap extension requires:
+// a thumbnail, a title, a description and a content or player URL that differs from the page URL.
+func validateVideo(pageURL string, video core.Video) error {
+
+	switch {
+	case len(video.ThumbnailURL) == 0:
+		return errors.New("no thumbnail")
+
+	case len(video.Title) == 0:
+		return errors.New("no title")
+
+	case len(video.Description) == 0:
+		return errors.New("no description")
+
+	case len(video.ContentURL) == 0 && len(video.PlayerURL) == 0:
+		return errors.New("no content or player URL")
+
+	case video.ContentURL == pageURL || video.PlayerURL == pageURL:
+		return fmt.Errorf("content or player URL is the same as the page URL")
+	}
+
+	return nil
+}
+
+// encodeLocs encodes non-empty URLs with encodeLoc. Empty URLs stay empty.
+func encodeLocs(urls ...string) ([]string, error) {
+
+	res := make([]string, len(urls))
+
+	for i, u := range urls {
+		if len(u) == 0 {
+			continue
+		}
+
+		loc, err := encodeLoc(u)
+		if err != nil {
+			return nil, fmt.Errorf("bad URL [%v]: %w", u, err)
+		}
+
+		res[i] = loc
+	}
+
+	return res, nil
+}
+
+// firstLoc returns a content URL of a video or a player URL if there's no content one.
+func firstLoc(video core.Video) string {
+	if len(video.ContentURL) > 0 {
+		return video.ContentURL
+	}
+
+	return video.PlayerURL
+}
@@ -0,0 +1,163 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
+)
+
+func TestReporter_validateVideo(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		video  core.Video
+		expErr bool
+	}
+
+	valid := core.Video{
+		ThumbnailURL: "http://e.com/thumb.jpg",
+		Title:        "Tutorial",
+		Description:  "How to",
+		ContentURL:   "http://e.com/video.mp4",
+	}
+
+	withField := func(f func(v *core.Video)) core.Video {
+		v := valid
+		f(&v)
+
+		return v
+	}
+
+	tests := map[string]Test{
+		"OK": {
+			video: valid,
+		},
+
+		"player only": {
+			video: withField(func(v *core.Video) { v.ContentURL, v.PlayerURL = "", "https://www.youtube.com/embed/abc" }),
+		},
+
+		"no thumbnail": {
+			video:  withField(func(v *core.Video) { v.ThumbnailURL = "" }),
+			expErr: true,
+		},
+
+		"no title": {
+			video:  withField(func(v *core.Video) { v.Title = "" }),
+			expErr: true,
+		},
+
+		"no description": {
+			video:  withField(func(v *core.Video) { v.Description = "" }),
+			expErr: true,
+		},
+
+		"no content and player": {
+			video:  withField(func(v *core.Video) { v.ContentURL = "" }),
+			expErr: true,
+		},
+
+		"content is the page": {
+			video:  withField(func(v *core.Video) { v.ContentURL = "http://e.com/page" }),
+			expErr: true,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateVideo("http://e.com/page", test.video)
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReporter_SaveVideos(t *testing.T) {
+	t.Parallel()
+
+	src := &core.PageItem{
+		URL: "http://e.com",
+		Children: []*core.PageItem{
+			{URL: "http://e.com/tutorial", Meta: core.PageMeta{Videos: []core.Video{
+				{
+					ThumbnailURL: "http://e.com/thumb.jpg",
+					Title:        "Tutorial <1>",
+					Description:  strings.Repeat("d", MaxVideoDescription+1),
+					ContentURL:   "http://e.com/video.mp4",
+					Duration:     90 * time.Second,
+				},
+				{
+					ThumbnailURL: "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+					Title:        "Embedded",
+					Description:  "Embedded video",
+					PlayerURL:    "https://www.youtube.com/embed/abc",
+					Duration:     MaxVideoDuration + time.Second,
+				},
+				{
+					Title:      "No thumbnail",
+					ContentURL: "http://e.com/invalid.mp4",
+				},
+			}}},
+		},
+	}
+
+	fileName := filepath.Join(t.TempDir(), "sitemap.xml")
+
+	require.NoError(t, New(Config{FileName: fileName, Videos: true}).Save(src))
+
+	buf := readFile(t, fileName)
+	require.Contains(t, string(buf), `xmlns:video="`+VideoXmlns+`"`)
+
+	type video struct {
+		ThumbnailLoc string `xml:"thumbnail_loc"`
+		Title        string `xml:"title"`
+		Description  string `xml:"description"`
+		ContentLoc   string `xml:"content_loc"`
+		PlayerLoc    string `xml:"player_loc"`
+		Duration     int    `xml:"duration"`
+	}
+
+	var us struct {
+		URLs []struct {
+			Loc    string  `xml:"loc"`
+			Videos []video `xml:"http://www.google.com/schemas/sitemap-video/1.1 video"`
+		} `xml:"url"`
+	}
+
+	require.NoError(t, xml.Unmarshal(buf, &us))
+	require.Len(t, us.URLs, 2)
+
+	require.Equal(t, "http://e.com/tutorial", us.URLs[0].Loc)
+	require.Equal(t, []video{
+		{
+			ThumbnailLoc: "http://e.com/thumb.jpg",
+			Title:        "Tutorial <1>",
+			Description:  strings.Repeat("d", MaxVideoDescription),
+			ContentLoc:   "http://e.com/video.mp4",
+			Duration:     90,
+		},
+		{
+			ThumbnailLoc: "https://i.ytimg.com/vi/abc/hqdefault.jpg",
+			Title:        "Embedded",
+			Description:  "Embedded video",
+			PlayerLoc:    "https://www.youtube.com/embed/abc",
+		},
+	}, us.URLs[0].Videos)
+
+	require.Empty(t, us.URLs[1].Videos)
+}
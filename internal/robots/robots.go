@@ -0,0 +1,251 @@
+package robots
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// AnyAgent is a user agent of the default group.
+	AnyAgent = "*"
+
+	// Path is a path of robots.txt file on a host.
+	Path = "/robots.txt"
+
+	keyUserAgent = "user-agent"
+	keyAllow     = "allow"
+	keyDisallow  = "disallow"
+	keyDelay     = "crawl-delay"
+	keySitemap   = "sitemap"
+)
+
+type (
+
+	// Robots is a parsed robots.txt file.
+	Robots struct {
+		groups []*Group
+
+		// Sitemaps are URLs from Sitemap: lines.
+		Sitemaps []string
+	}
+
+	// Group is a set of rules for a user agent.
+	Group struct {
+		agents []string
+		rules  []rule
+
+		// CrawlDelay is a delay between requests to a host. Zero means no delay.
+		CrawlDelay time.Duration
+	}
+
+	rule struct {
+		allow bool
+		path  string
+		re    *regexp.Regexp
+	}
+)
+
+// Parse reads robots.txt content.
+// Lines it doesn't understand are ignored as RFC 9309 requires.
+func Parse(r io.Reader) (*Robots, error) {
+
+	res := Robots{}
+
+	var cur *Group
+
+	// groupStarted is true while user-agent lines follow each other,
+	// so they all belong to the same group.
+	groupStarted := false
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+
+		case keyUserAgent:
+			if !groupStarted {
+				cur = &Group{}
+				res.groups = append(res.groups, cur)
+				groupStarted = true
+			}
+
+			cur.agents = append(cur.agents, strings.ToLower(value))
+
+		case keyAllow, keyDisallow:
+			groupStarted = false
+
+			// rules out of any group and empty paths are ignored
+			if cur == nil || len(value) == 0 {
+				continue
+			}
+
+			cur.rules = append(cur.rules, rule{
+				allow: key == keyAllow,
+				path:  value,
+				re:    pathToRegexp(value),
+			})
+
+		case keyDelay:
+			groupStarted = false
+
+			if cur == nil {
+				continue
+			}
+
+			delay, err := strconv.ParseFloat(value, 64)
+			if err != nil || delay < 0 {
+				continue
+			}
+
+			cur.CrawlDelay = time.Duration(delay * float64(time.Second))
+
+		case keySitemap:
+			// sitemaps don't belong to groups
+			res.Sitemaps = append(res.Sitemaps, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read robots.txt: %w", err)
+	}
+
+	return &res, nil
+}
+
+// ProductToken returns a crawler name of a User-Agent header value that robots.txt groups are matched against.
+// It's a name of the first product, e.g. "mybot" of "mybot/1.0 (+http://e.com/bot)".
+func ProductToken(userAgent string) string {
+
+	userAgent = strings.TrimSpace(userAgent)
+
+	if i := strings.IndexAny(userAgent, "/ ("); i >= 0 {
+		return userAgent[:i]
+	}
+
+	return userAgent
+}
+
+// Group returns rules for the crawler product token (see ProductToken).
+// A group which agent equals the token case-insensitively is chosen, otherwise the default (*) group is used.
+// Groups with the same agent are merged. Group returns nil if no group applies, that means everything is allowed.
+func (r *Robots) Group(productToken string) *Group {
+
+	if r == nil {
+		return nil
+	}
+
+	productToken = strings.ToLower(strings.TrimSpace(productToken))
+
+	var matched, defaultGroups []*Group
+
+	for _, g := range r.groups {
+		for _, agent := range g.agents {
+
+			switch {
+
+			case agent == AnyAgent:
+				defaultGroups = append(defaultGroups, g)
+
+			case len(agent) > 0 && agent == productToken:
+				matched = append(matched, g)
+			}
+		}
+	}
+
+	if len(matched) == 0 {
+		matched = defaultGroups
+	}
+
+	return mergeGroups(matched)
+}
+
+// Allowed checks if the path (including query) is allowed by the group.
+// The longest matching rule wins, in case of tie Allow wins.
+// If the path is disallowed Allowed returns a rule that disallows it.
+func (g *Group) Allowed(path string) (bool, string) {
+
+	if g == nil || path == Path {
+		return true, ""
+	}
+
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	var best *rule
+
+	for i, r := range g.rules {
+
+		if !r.re.MatchString(path) {
+			continue
+		}
+
+		if best == nil || len(r.path) > len(best.path) || (len(r.path) == len(best.path) && r.allow) {
+			best = &g.rules[i]
+		}
+	}
+
+	if best == nil || best.allow {
+		return true, ""
+	}
+
+	return false, fmt.Sprintf("Disallow: %v", best.path)
+}
+
+func mergeGroups(groups []*Group) *Group {
+
+	switch len(groups) {
+	case 0:
+		return nil
+	case 1:
+		return groups[0]
+	}
+
+	res := Group{}
+
+	for _, g := range groups {
+		res.agents = append(res.agents, g.agents...)
+		res.rules = append(res.rules, g.rules...)
+
+		if g.CrawlDelay > res.CrawlDelay {
+			res.CrawlDelay = g.CrawlDelay
+		}
+	}
+
+	return &res
+}
+
+// pathToRegexp converts a rule path to a regular expression.
+// * matches any sequence of symbols, $ at the end anchors the path end.
+func pathToRegexp(path string) *regexp.Regexp {
+
+	anchored := strings.HasSuffix(path, "$")
+	path = strings.TrimSuffix(path, "$")
+
+	parts := strings.Split(path, "*")
+	for i, p := range parts {
+		parts[i] = regexp.QuoteMeta(p)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+
+	return regexp.MustCompile(expr)
+}
@@ -0,0 +1,113 @@
+package robots
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const robotsTxt = `# test robots.txt
+User-agent: *
+Disallow: /admin
+Disallow: /search?
+Allow: /admin/public
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: sitemap-generator
+User-agent: other-bot
+Disallow: /private # comment
+Allow: /private/open
+Crawl-delay: 0.5
+
+User-agent: greedy-bot
+Disallow: /
+
+User-agent: map
+Disallow: /
+
+Sitemap: http://e.com/sitemap.xml
+Sitemap: http://e.com/news.xml
+`
+
+func TestRobots_Allowed(t *testing.T) {
+	t.Parallel()
+
+	r, err := Parse(strings.NewReader(robotsTxt))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"http://e.com/sitemap.xml", "http://e.com/news.xml"}, r.Sitemaps)
+
+	type Test struct {
+		userAgent string
+		path      string
+		expRes    bool
+		expReason string
+	}
+
+	tests := map[string]Test{
+		"default allowed":          {userAgent: "some-bot", path: "/page", expRes: true},
+		"default disallowed":       {userAgent: "some-bot", path: "/admin/users", expRes: false, expReason: "Disallow: /admin"},
+		"longest allow wins":       {userAgent: "some-bot", path: "/admin/public/page", expRes: true},
+		"query":                    {userAgent: "some-bot", path: "/search?q=go", expRes: false, expReason: "Disallow: /search?"},
+		"path without query":       {userAgent: "some-bot", path: "/search", expRes: true},
+		"wildcard with end":        {userAgent: "some-bot", path: "/files/doc.pdf", expRes: false, expReason: "Disallow: /*.pdf$"},
+		"wildcard with end prefix": {userAgent: "some-bot", path: "/files/doc.pdf.html", expRes: true},
+		"specific group":           {userAgent: "Sitemap-Generator", path: "/private/page", expRes: false, expReason: "Disallow: /private"},
+		"part of agent name":       {userAgent: "sitemap-generator", path: "/page", expRes: true},
+		"agent name contains it":   {userAgent: "greedy-bot-2", path: "/page", expRes: true},
+		"specific group allow":     {userAgent: "sitemap-generator", path: "/private/open/page", expRes: true},
+		"specific group ignores *": {userAgent: "sitemap-generator", path: "/admin", expRes: true},
+		"second agent of group":    {userAgent: "other-bot", path: "/private", expRes: false, expReason: "Disallow: /private"},
+		"disallow all":             {userAgent: "greedy-bot", path: "/", expRes: false, expReason: "Disallow: /"},
+		"robots.txt is allowed":    {userAgent: "greedy-bot", path: "/robots.txt", expRes: true},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			res, reason := r.Group(test.userAgent).Allowed(test.path)
+
+			require.Equal(t, test.expRes, res)
+			require.Equal(t, test.expReason, reason)
+		})
+	}
+}
+
+func TestProductToken(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "sitemap-generator", ProductToken("sitemap-generator"))
+	require.Equal(t, "mybot", ProductToken(" mybot/1.0 (+http://e.com/bot)"))
+	require.Equal(t, "Mozilla", ProductToken("Mozilla/5.0 (compatible; sitemap-generator/1.0)"))
+}
+
+func TestRobots_CrawlDelay(t *testing.T) {
+	t.Parallel()
+
+	r, err := Parse(strings.NewReader(robotsTxt))
+	require.NoError(t, err)
+
+	require.Equal(t, 2*time.Second, r.Group("some-bot").CrawlDelay)
+	require.Equal(t, 500*time.Millisecond, r.Group("sitemap-generator").CrawlDelay)
+	require.Equal(t, time.Duration(0), r.Group("greedy-bot").CrawlDelay)
+}
+
+func TestRobots_Empty(t *testing.T) {
+	t.Parallel()
+
+	r, err := Parse(strings.NewReader(""))
+	require.NoError(t, err)
+
+	g := r.Group("sitemap-generator")
+	require.Nil(t, g)
+
+	res, _ := g.Allowed("/admin")
+	require.True(t, res)
+}
@@ -0,0 +1,229 @@
+package urlnorm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// TrailingSlash is a policy of trailing slashes in URL paths.
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep leaves paths as is.
+	TrailingSlashKeep TrailingSlash = iota
+
+	// TrailingSlashAdd adds a slash to paths which last segment doesn't look like a file name (has no dot).
+	TrailingSlashAdd
+
+	// TrailingSlashRemove removes a trailing slash from all paths but the root one.
+	TrailingSlashRemove
+)
+
+// DefaultStripParams are common tracking parameters.
+var DefaultStripParams = []string{
+	"utm_*",
+	"gclid",
+	"fbclid",
+	"yclid",
+	"msclkid",
+	"mc_cid",
+	"mc_eid",
+	"_ga",
+}
+
+// defaultPorts are ports that are dropped for schemes.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+type (
+	Config struct {
+		// StripParams are names of query parameters that are removed.
+		// A name that ends with * matches all parameters with this prefix.
+		StripParams []string
+
+		TrailingSlash TrailingSlash
+	}
+
+	// Normalizer brings URLs to a canonical form, so URLs of the same page are equal.
+	Normalizer struct {
+		config Config
+	}
+)
+
+func New(config Config) *Normalizer {
+	return &Normalizer{
+		config: config,
+	}
+}
+
+// ParseTrailingSlash parses trailing slash policy name: keep, add or remove.
+func ParseTrailingSlash(s string) (TrailingSlash, error) {
+	switch strings.ToLower(s) {
+	case "", "keep":
+		return TrailingSlashKeep, nil
+	case "add":
+		return TrailingSlashAdd, nil
+	case "remove":
+		return TrailingSlashRemove, nil
+	default:
+		return TrailingSlashKeep, fmt.Errorf("unknown trailing slash policy [%v]", s)
+	}
+}
+
+// Normalize lowercases scheme and host, drops default port and fragment, resolves dot segments,
+// strips configured query parameters, sorts the rest of them and applies trailing slash policy.
+func (n *Normalizer) Normalize(rawURL string) (string, error) {
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("bad URL [%v]: %w", rawURL, err)
+	}
+
+	if !u.IsAbs() {
+		return "", fmt.Errorf("URL [%v] is not absolute", rawURL)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = n.normalizeHost(u.Scheme, u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	// resolving against an empty reference removes dot segments and keeps the query
+	u = u.ResolveReference(&url.URL{})
+
+	if len(u.Path) == 0 {
+		u.Path = "/"
+		u.RawPath = ""
+	}
+
+	n.applyTrailingSlash(u)
+
+	u.RawQuery = n.normalizeQuery(u.RawQuery)
+	u.ForceQuery = false
+
+	return u.String(), nil
+}
+
+func (n *Normalizer) normalizeHost(scheme, host string) string {
+
+	host = strings.ToLower(host)
+
+	hostName, port, err := net.SplitHostPort(host)
+	if err != nil {
+		// host has no port
+		return host
+	}
+
+	if defaultPort, ok := defaultPorts[scheme]; ok && port == defaultPort {
+		if strings.Contains(hostName, ":") {
+			// IPv6 address
+			return "[" + hostName + "]"
+		}
+
+		return hostName
+	}
+
+	return host
+}
+
+func (n *Normalizer) applyTrailingSlash(u *url.URL) {
+
+	p := u.EscapedPath()
+
+	switch n.config.TrailingSlash {
+
+	case TrailingSlashAdd:
+		if strings.HasSuffix(p, "/") || strings.Contains(path.Base(p), ".") {
+			return
+		}
+
+		p += "/"
+
+	case TrailingSlashRemove:
+		if p == "/" || !strings.HasSuffix(p, "/") {
+			return
+		}
+
+		p = strings.TrimRight(p, "/")
+		if len(p) == 0 {
+			p = "/"
+		}
+
+	default:
+		return
+	}
+
+	if unescaped, err := url.PathUnescape(p); err == nil {
+		u.Path = unescaped
+		u.RawPath = p
+	}
+}
+
+// normalizeQuery strips configured parameters and sorts the rest of them by name.
+// Parameters with the same name keep their order. Parameters encoding is not changed.
+func (n *Normalizer) normalizeQuery(rawQuery string) string {
+
+	if len(rawQuery) == 0 {
+		return ""
+	}
+
+	params := strings.Split(rawQuery, "&")
+	res := make([]string, 0, len(params))
+
+	for _, param := range params {
+
+		if len(param) == 0 {
+			continue
+		}
+
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+
+		if n.isStripped(name) {
+			continue
+		}
+
+		res = append(res, param)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		ni, _, _ := strings.Cut(res[i], "=")
+		nj, _, _ := strings.Cut(res[j], "=")
+
+		return ni < nj
+	})
+
+	return strings.Join(res, "&")
+}
+
+func (n *Normalizer) isStripped(name string) bool {
+
+	name = strings.ToLower(name)
+
+	for _, p := range n.config.StripParams {
+
+		p = strings.ToLower(p)
+
+		if prefix := strings.TrimSuffix(p, "*"); prefix != p {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+
+			continue
+		}
+
+		if name == p {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package urlnorm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		config Config
+		src    string
+		expRes string
+		expErr bool
+	}
+
+	tests := map[string]Test{
+		"scheme and host case": {
+			src:    "HTTP://Host.E.com/Path",
+			expRes: "http://host.e.com/Path",
+		},
+
+		"default port": {
+			src:    "https://host.e.com:443/a",
+			expRes: "https://host.e.com/a",
+		},
+
+		"non-default port": {
+			src:    "http://host.e.com:8080/a",
+			expRes: "http://host.e.com:8080/a",
+		},
+
+		"empty path": {
+			src:    "http://host.e.com",
+			expRes: "http://host.e.com/",
+		},
+
+		"dot segments": {
+			src:    "http://host.e.com/a/./b/../c",
+			expRes: "http://host.e.com/a/c",
+		},
+
+		"fragment": {
+			src:    "http://host.e.com/a#section",
+			expRes: "http://host.e.com/a",
+		},
+
+		"sorted query": {
+			src:    "http://host.e.com/a?b=2&a=1&b=1&c",
+			expRes: "http://host.e.com/a?a=1&b=2&b=1&c",
+		},
+
+		"stripped params": {
+			config: Config{StripParams: DefaultStripParams},
+			src:    "http://host.e.com/a?utm_source=x&id=1&UTM_Medium=y&gclid=z",
+			expRes: "http://host.e.com/a?id=1",
+		},
+
+		"only stripped params": {
+			config: Config{StripParams: DefaultStripParams},
+			src:    "http://host.e.com/a?utm_source=x",
+			expRes: "http://host.e.com/a",
+		},
+
+		"add trailing slash": {
+			config: Config{TrailingSlash: TrailingSlashAdd},
+			src:    "http://host.e.com/a/b?x=1",
+			expRes: "http://host.e.com/a/b/?x=1",
+		},
+
+		"add trailing slash to file": {
+			config: Config{TrailingSlash: TrailingSlashAdd},
+			src:    "http://host.e.com/a/b.html",
+			expRes: "http://host.e.com/a/b.html",
+		},
+
+		"remove trailing slash": {
+			config: Config{TrailingSlash: TrailingSlashRemove},
+			src:    "http://host.e.com/a/b/",
+			expRes: "http://host.e.com/a/b",
+		},
+
+		"remove trailing slash of root": {
+			config: Config{TrailingSlash: TrailingSlashRemove},
+			src:    "http://host.e.com/",
+			expRes: "http://host.e.com/",
+		},
+
+		"escaped path": {
+			config: Config{TrailingSlash: TrailingSlashAdd},
+			src:    "http://host.e.com/a%2Fb",
+			expRes: "http://host.e.com/a%2Fb/",
+		},
+
+		"relative URL": {
+			src:    "/a/b",
+			expErr: true,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := New(test.config).Normalize(test.src)
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expRes, res)
+		})
+	}
+}
+
+func TestParseTrailingSlash(t *testing.T) {
+	t.Parallel()
+
+	ts, err := ParseTrailingSlash("Remove")
+	require.NoError(t, err)
+	require.Equal(t, TrailingSlashRemove, ts)
+
+	_, err = ParseTrailingSlash("sometimes")
+	require.Error(t, err)
+}
@@ -4,37 +4,147 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	neturl "net/url"
 	"os"
 	"os/signal"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/loader"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/ratelimit"
 	"github.com/yurii-vyrovyi/sitemap-generator/internal/reporter"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/robots"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/urlnorm"
 )
 
 const (
 	Help = `usage
-sitemap-generator <url> [-parallel=...] [-output-file=...] [-max-depth=...]
+sitemap-generator <url> [-option=value ...]
 
 url				an url of website you want to build sitemap of
 
 optional
 	-parallel=		number of parallel workers to navigate through site
-	-output-file=		output file path
+	-output-file=		output file path (default ./sitemap.xml)
+	-format=		output format: xml, json, csv, txt or html (default is chosen by -output-file extension, xml for unknown ones)
 	-max-depth=		max depth of url navigation recursion
+	-base-url=		url where sitemap files are published, used for child sitemaps in a sitemap index
+	-gzip=			true to save gzip-compressed sitemap files
+	-images=		true to save page images as image sitemap entries
+	-videos=		true to save page videos as video sitemap entries
+	-hreflang=		true to save hreflang alternates of localized pages
+	-news-file=		news sitemap file path, a news sitemap is saved only if it's set
+	-news-name=		news publication name (default is taken from articles)
+	-news-language=		news publication language (default is taken from articles)
+	-rules-file=		JSON file with changefreq and priority rules
+	-ignore-robots=		true to crawl pages disallowed by robots.txt
+	-include=		comma-separated list of URL path patterns to crawl, \, is a comma in a pattern; may be repeated
+	-exclude=		comma-separated list of URL path patterns not to crawl, \, is a comma in a pattern; may be repeated
+	-normalize=		false to disable URLs normalization (default true)
+	-strip-params=		comma-separated list of query parameters to strip, * suffix matches a prefix (default utm_*,gclid,...)
+	-trailing-slash=	trailing slash policy: keep, add or remove (default keep)
+	-honor-nofollow=	true to skip links with rel="nofollow"
+	-seed-sitemaps=		true to crawl pages from sitemaps listed in robots.txt and -sitemaps
+	-sitemaps=		comma-separated list of existing sitemaps to seed the crawl from
+	-seed-level=		depth of seeded pages, 0 or 1 (default 1)
+	-scope=			hosts to crawl: host, www, subdomains or allowlist (default host)
+	-allowed-hosts=		comma-separated list of hosts to crawl with -scope=allowlist
+	-rps=			max requests per second to a host, e.g. 0.5 (default unlimited)
+	-max-in-flight=		max concurrent requests to a host (default unlimited)
+	-jitter=		max random delay added to every request, e.g. 500ms
+	-max-retries=		max number of retries of a URL on network errors, 429 and 5xx (default 3)
+	-retry-budget=		max number of retries of all URLs (default unlimited)
+	-retry-delay=		delay before the first retry, it's doubled for every next one (default 1s)
+	-retry-max-delay=	max delay between retries including Retry-After (default 30s)
+	-max-body-size=		max size of a page in bytes that is read, the rest is ignored (default 10MiB)
+	-max-redirects=		max number of redirects that are followed, 0 disables following redirects (default 10)
+	-link-sources=		comma-separated list of link sources: a, area, link, iframe, frame, meta-refresh, data-href (default a)
+	-render-url=		render service URL that returns HTML of JavaScript-rendered pages, {url} is replaced with a page URL
+	-timeout=		timeout of a request including reading a page (default 30s)
+	-connect-timeout=	timeout of establishing a connection (default 10s)
+	-user-agent=		User-Agent header (default sitemap-generator)
+	-robots-agent=		crawler name robots.txt groups are matched against (default is the -user-agent product name, e.g. mybot of mybot/1.0)
+	-header=		extra request header "Name: value", may be repeated
+	-cookie=		request cookie "name=value", may be repeated
+	-proxy=			http, https or socks5 proxy URL (default is taken from HTTP_PROXY, HTTPS_PROXY and NO_PROXY)
+	-ca-file=		PEM file with trusted CA certificates, may be repeated
+	-insecure-skip-verify=	true to skip TLS certificates verification
+	-auth-user=		HTTP basic auth user
+	-auth-password=		HTTP basic auth password
+	-bearer-token=		bearer token that is sent in Authorization header
+	-cookie-file=		Netscape cookies file, e.g. exported from a browser
+	-login-url=		login form page that is submitted before the crawl
+	-login-field=		login form field "name=value", may be repeated
 
 `
 
-	ParamParallel   = "parallel"
-	ParamOutputFile = "output-file"
-	ParamMaxDepth   = "max-depth"
+	ParamParallel      = "parallel"
+	ParamOutputFile    = "output-file"
+	ParamFormat        = "format"
+	ParamMaxDepth      = "max-depth"
+	ParamBaseURL       = "base-url"
+	ParamGzip          = "gzip"
+	ParamImages        = "images"
+	ParamVideos        = "videos"
+	ParamHreflang      = "hreflang"
+	ParamNewsFile      = "news-file"
+	ParamNewsName      = "news-name"
+	ParamNewsLanguage  = "news-language"
+	ParamRulesFile     = "rules-file"
+	ParamIgnoreRobots  = "ignore-robots"
+	ParamInclude       = "include"
+	ParamExclude       = "exclude"
+	ParamNormalize     = "normalize"
+	ParamStripParams   = "strip-params"
+	ParamTrailingSlash = "trailing-slash"
+	ParamHonorNofollow = "honor-nofollow"
+	ParamSeedSitemaps  = "seed-sitemaps"
+	ParamSitemaps      = "sitemaps"
+	ParamSeedLevel     = "seed-level"
+	ParamScope         = "scope"
+	ParamAllowedHosts  = "allowed-hosts"
+	ParamRPS           = "rps"
+	ParamMaxInFlight   = "max-in-flight"
+	ParamJitter        = "jitter"
+	ParamMaxRetries    = "max-retries"
+	ParamRetryBudget   = "retry-budget"
+	ParamRetryDelay    = "retry-delay"
+	ParamRetryMaxDelay = "retry-max-delay"
+	ParamMaxBodySize   = "max-body-size"
+	ParamMaxRedirects  = "max-redirects"
+	ParamLinkSources   = "link-sources"
+	ParamRenderURL     = "render-url"
+	ParamTimeout       = "timeout"
+	ParamConnTimeout   = "connect-timeout"
+	ParamUserAgent     = "user-agent"
+	ParamRobotsAgent   = "robots-agent"
+	ParamHeader        = "header"
+	ParamCookie        = "cookie"
+	ParamProxy         = "proxy"
+	ParamCAFile        = "ca-file"
+	ParamInsecure      = "insecure-skip-verify"
+	ParamAuthUser      = "auth-user"
+	ParamAuthPassword  = "auth-password"
+	ParamBearerToken   = "bearer-token"
+	ParamCookieFile    = "cookie-file"
+	ParamLoginURL      = "login-url"
+	ParamLoginField    = "login-field"
 
 	DefaultParallel   = 5
-	DefaultOutputFile = "./sitemap.json"
+	DefaultOutputFile = "./sitemap.xml"
 	DefaultMaxDepth   = 3
+	DefaultSeedLevel  = 1
+
+	DefaultMaxRetries    = 3
+	DefaultRetryDelay    = time.Second
+	DefaultRetryMaxDelay = 30 * time.Second
+
+	// DefaultUserAgent is a name of the crawler that is sent in User-Agent header and is used to choose robots.txt rules.
+	DefaultUserAgent = "sitemap-generator"
 )
 
 func main() {
@@ -59,9 +169,57 @@ func run(ctx context.Context) error {
 	url := args[0]
 
 	mapKeys := map[string]interface{}{
-		ParamParallel:   0,
-		ParamOutputFile: "",
-		ParamMaxDepth:   0,
+		ParamParallel:      0,
+		ParamOutputFile:    "",
+		ParamFormat:        "",
+		ParamMaxDepth:      0,
+		ParamBaseURL:       "",
+		ParamGzip:          false,
+		ParamImages:        false,
+		ParamVideos:        false,
+		ParamHreflang:      false,
+		ParamNewsFile:      "",
+		ParamNewsName:      "",
+		ParamNewsLanguage:  "",
+		ParamRulesFile:     "",
+		ParamIgnoreRobots:  false,
+		ParamInclude:       []string(nil),
+		ParamExclude:       []string(nil),
+		ParamNormalize:     false,
+		ParamStripParams:   "",
+		ParamTrailingSlash: "",
+		ParamHonorNofollow: false,
+		ParamSeedSitemaps:  false,
+		ParamSitemaps:      "",
+		ParamSeedLevel:     0,
+		ParamScope:         "",
+		ParamAllowedHosts:  "",
+		ParamRPS:           0.0,
+		ParamMaxInFlight:   0,
+		ParamJitter:        "",
+		ParamMaxRetries:    0,
+		ParamRetryBudget:   0,
+		ParamRetryDelay:    "",
+		ParamRetryMaxDelay: "",
+		ParamMaxBodySize:   0,
+		ParamMaxRedirects:  0,
+		ParamLinkSources:   "",
+		ParamRenderURL:     "",
+		ParamTimeout:       "",
+		ParamConnTimeout:   "",
+		ParamUserAgent:     "",
+		ParamRobotsAgent:   "",
+		ParamHeader:        []string(nil),
+		ParamCookie:        []string(nil),
+		ParamProxy:         "",
+		ParamCAFile:        []string(nil),
+		ParamInsecure:      false,
+		ParamAuthUser:      "",
+		ParamAuthPassword:  "",
+		ParamBearerToken:   "",
+		ParamCookieFile:    "",
+		ParamLoginURL:      "",
+		ParamLoginField:    []string(nil),
 	}
 
 	argsMap, err := parseArgs(args[1:], mapKeys)
@@ -96,52 +254,437 @@ func run(ctx context.Context) error {
 		outputFile = DefaultOutputFile
 	}
 
-	pageLoader := loader.New()
-	reportSaver := reporter.New(reporter.Config{
+	gz := argsMap[ParamGzip]
+	compress, _ := gz.(bool) //nolint:errcheck
+
+	im := argsMap[ParamImages]
+	images, _ := im.(bool) //nolint:errcheck
+
+	vd := argsMap[ParamVideos]
+	videos, _ := vd.(bool) //nolint:errcheck
+
+	hl := argsMap[ParamHreflang]
+	hreflang, _ := hl.(bool) //nolint:errcheck
+
+	var rules *reporter.Rules
+
+	rf := argsMap[ParamRulesFile]
+	if rulesFile, _ := rf.(string); len(rulesFile) > 0 { //nolint:errcheck
+		rules, err = reporter.LoadRules(rulesFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	maxRetries := DefaultMaxRetries
+	if mr, ok := argsMap[ParamMaxRetries]; ok {
+		maxRetries, _ = mr.(int) //nolint:errcheck
+	}
+
+	rb := argsMap[ParamRetryBudget]
+	retryBudget, _ := rb.(int) //nolint:errcheck
+
+	retryDelay, err := durationArg(argsMap, ParamRetryDelay, DefaultRetryDelay)
+	if err != nil {
+		return err
+	}
+
+	retryMaxDelay, err := durationArg(argsMap, ParamRetryMaxDelay, DefaultRetryMaxDelay)
+	if err != nil {
+		return err
+	}
+
+	mb := argsMap[ParamMaxBodySize]
+	maxBodySize, _ := mb.(int) //nolint:errcheck
+
+	// maxRedirects stays nil unless the arg is set, so zero value disables following redirects.
+	var maxRedirects *int
+
+	mrd, ok := argsMap[ParamMaxRedirects]
+	if ok {
+		n, _ := mrd.(int) //nolint:errcheck
+		maxRedirects = &n
+	}
+
+	if maxRetries < 0 || retryBudget < 0 || maxBodySize < 0 || (maxRedirects != nil && *maxRedirects < 0) {
+		return fmt.Errorf("args [%v], [%v], [%v] and [%v] should not be negative",
+			ParamMaxRetries, ParamRetryBudget, ParamMaxBodySize, ParamMaxRedirects)
+	}
+
+	ls := argsMap[ParamLinkSources]
+	linkSources, _ := ls.(string) //nolint:errcheck
+
+	timeout, err := durationArg(argsMap, ParamTimeout, 0)
+	if err != nil {
+		return err
+	}
+
+	connectTimeout, err := durationArg(argsMap, ParamConnTimeout, 0)
+	if err != nil {
+		return err
+	}
+
+	ua := argsMap[ParamUserAgent]
+	userAgent, _ := ua.(string) //nolint:errcheck
+
+	if len(userAgent) == 0 {
+		userAgent = DefaultUserAgent
+	}
+
+	ra := argsMap[ParamRobotsAgent]
+	robotsAgent, _ := ra.(string) //nolint:errcheck
+
+	if len(robotsAgent) == 0 {
+		robotsAgent = robots.ProductToken(userAgent)
+	}
+
+	hs := argsMap[ParamHeader]
+	headersList, _ := hs.([]string) //nolint:errcheck
+
+	headers, err := parseHeaders(headersList)
+	if err != nil {
+		return err
+	}
+
+	cs := argsMap[ParamCookie]
+	cookiesList, _ := cs.([]string) //nolint:errcheck
+
+	cookies, err := parseCookies(cookiesList)
+	if err != nil {
+		return err
+	}
+
+	px := argsMap[ParamProxy]
+	proxy, _ := px.(string) //nolint:errcheck
+
+	ca := argsMap[ParamCAFile]
+	caFiles, _ := ca.([]string) //nolint:errcheck
+
+	isv := argsMap[ParamInsecure]
+	insecureSkipVerify, _ := isv.(bool) //nolint:errcheck
+
+	var renderer loader.Renderer
+
+	ru := argsMap[ParamRenderURL]
+	if renderURL, _ := ru.(string); len(renderURL) > 0 { //nolint:errcheck
+		sr, err := loader.NewServiceRenderer(renderURL, loader.Config{
+			MaxBodySize:        int64(maxBodySize),
+			Timeout:            timeout,
+			ConnectTimeout:     connectTimeout,
+			Proxy:              proxy,
+			CAFiles:            caFiles,
+			InsecureSkipVerify: insecureSkipVerify,
+		})
+		if err != nil {
+			return fmt.Errorf("bad arg [%v]: %w", ParamRenderURL, err)
+		}
+
+		renderer = sr
+	}
+
+	sc := argsMap[ParamScope]
+	scopeName, _ := sc.(string) //nolint:errcheck
+
+	scope, err := core.ParseScopeMode(scopeName)
+	if err != nil {
+		return fmt.Errorf("bad arg [%v]: %w", ParamScope, err)
+	}
+
+	ah := argsMap[ParamAllowedHosts]
+	allowedHosts, _ := ah.(string) //nolint:errcheck
+
+	// siteScope host is the start URL host until the start URL is resolved
+	siteScope := core.Scope{Mode: scope, AllowedHosts: splitList(allowedHosts)}
+
+	if siteScope.Host, err = urlHost(url); err != nil {
+		return err
+	}
+
+	authScope, err := newAuthScope(&siteScope, argsMap)
+	if err != nil {
+		return err
+	}
+
+	au := argsMap[ParamAuthUser]
+	authUser, _ := au.(string) //nolint:errcheck
+
+	bp := argsMap[ParamAuthPassword]
+	authPassword, _ := bp.(string) //nolint:errcheck
+
+	bt := argsMap[ParamBearerToken]
+	bearerToken, _ := bt.(string) //nolint:errcheck
+
+	cf := argsMap[ParamCookieFile]
+	cookieFile, _ := cf.(string) //nolint:errcheck
+
+	var login *loader.FormLogin
+
+	lu := argsMap[ParamLoginURL]
+	if loginURL, _ := lu.(string); len(loginURL) > 0 { //nolint:errcheck
+		lf := argsMap[ParamLoginField]
+		loginFields, _ := lf.([]string) //nolint:errcheck
+
+		fields, err := parseFields(loginFields)
+		if err != nil {
+			return err
+		}
+
+		login = &loader.FormLogin{
+			URL:    loginURL,
+			Fields: fields,
+		}
+	}
+
+	r := argsMap[ParamRPS]
+	rps, _ := r.(float64) //nolint:errcheck
+
+	mf := argsMap[ParamMaxInFlight]
+	maxInFlight, _ := mf.(int) //nolint:errcheck
+
+	jitter, err := durationArg(argsMap, ParamJitter, 0)
+	if err != nil {
+		return err
+	}
+
+	if rps < 0 || maxInFlight < 0 {
+		return fmt.Errorf("args [%v] and [%v] should not be negative", ParamRPS, ParamMaxInFlight)
+	}
+
+	limiter := ratelimit.New(ratelimit.Config{
+		RPS:         rps,
+		MaxInFlight: maxInFlight,
+		Jitter:      jitter,
+	})
+
+	pageLoader, err := loader.New(loader.Config{
+		MaxRetries:         maxRetries,
+		RetryBudget:        retryBudget,
+		RetryBaseDelay:     retryDelay,
+		RetryMaxDelay:      retryMaxDelay,
+		MaxBodySize:        int64(maxBodySize),
+		MaxRedirects:       maxRedirects,
+		Limiter:            limiter,
+		LinkSources:        splitList(linkSources),
+		Renderer:           renderer,
+		Timeout:            timeout,
+		ConnectTimeout:     connectTimeout,
+		UserAgent:          userAgent,
+		Headers:            headers,
+		Cookies:            cookies,
+		BasicUser:          authUser,
+		BasicPassword:      authPassword,
+		BearerToken:        bearerToken,
+		CookieFile:         cookieFile,
+		Login:              login,
+		AuthScope:          authScope,
+		RedirectScope:      func(u *neturl.URL) bool { return siteScope.Contains(u) },
+		Proxy:              proxy,
+		CAFiles:            caFiles,
+		InsecureSkipVerify: insecureSkipVerify,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := pageLoader.Login(ctx); err != nil {
+		return fmt.Errorf("failed to login: %w", err)
+	}
+
+	// the start page may redirect to another host (e.g. http://e.com to https://www.e.com),
+	// so the crawl scope, credentials scope and seeds are built for the final URL
+	startURL, err := pageLoader.ResolveURL(ctx, url)
+	if err != nil {
+		log.Printf("ERR: failed to resolve start URL [%v]: %v", url, err)
+		startURL = url
+	}
+
+	if startURL != url {
+		log.Printf("start page [%v] redirects to [%v]", url, startURL)
+
+		if siteScope.Host, err = startHost(siteScope, startURL); err != nil {
+			return err
+		}
+	}
+
+	// child sitemaps are on the same host as the pages, so the default base URL is taken from the final start URL
+	baseURL, err := baseURLArg(argsMap, startURL)
+	if err != nil {
+		return err
+	}
+
+	fm := argsMap[ParamFormat]
+	format, _ := fm.(string) //nolint:errcheck
+
+	outputSaver, err := reporter.NewFormat(format, reporter.Config{
 		FileName: outputFile,
+		BaseURL:  baseURL,
+		Compress: compress,
+		Rules:    rules,
+		Images:   images,
+		Videos:   videos,
+		Hreflang: hreflang,
 	})
+	if err != nil {
+		return fmt.Errorf("bad arg [%v]: %w", ParamFormat, err)
+	}
+
+	reportSaver := reporter.Multi{outputSaver}
+
+	nf := argsMap[ParamNewsFile]
+	if newsFile, _ := nf.(string); len(newsFile) > 0 { //nolint:errcheck
+		nn := argsMap[ParamNewsName]
+		newsName, _ := nn.(string) //nolint:errcheck
+
+		nl := argsMap[ParamNewsLanguage]
+		newsLanguage, _ := nl.(string) //nolint:errcheck
+
+		reportSaver = append(reportSaver, reporter.NewNewsWriter(reporter.NewsConfig{
+			FileName:        newsFile,
+			PublicationName: newsName,
+			Language:        newsLanguage,
+			Compress:        compress,
+		}))
+	}
+
+	ir := argsMap[ParamIgnoreRobots]
+	ignoreRobots, _ := ir.(bool) //nolint:errcheck
+
+	hn := argsMap[ParamHonorNofollow]
+	honorNofollow, _ := hn.(bool) //nolint:errcheck
+
+	ss := argsMap[ParamSeedSitemaps]
+	seedSitemaps, _ := ss.(bool) //nolint:errcheck
+
+	sm := argsMap[ParamSitemaps]
+	sitemapsList, _ := sm.(string) //nolint:errcheck
+
+	seedLevel := DefaultSeedLevel
+	if sl, ok := argsMap[ParamSeedLevel]; ok {
+		seedLevel, _ = sl.(int) //nolint:errcheck
+	}
+
+	if seedLevel != 0 && seedLevel != 1 {
+		return fmt.Errorf("arg [%v] should be 0 or 1 [%v]", ParamSeedLevel, seedLevel)
+	}
+
+	inc := argsMap[ParamInclude]
+	include, _ := inc.([]string) //nolint:errcheck
+
+	exc := argsMap[ParamExclude]
+	exclude, _ := exc.([]string) //nolint:errcheck
+
+	normalize := true
+	if n, ok := argsMap[ParamNormalize]; ok {
+		normalize, _ = n.(bool) //nolint:errcheck
+	}
+
+	stripParams := urlnorm.DefaultStripParams
+	if sp, ok := argsMap[ParamStripParams]; ok {
+		stripParamsList, _ := sp.(string) //nolint:errcheck
+		stripParams = splitList(stripParamsList)
+	}
+
+	ts := argsMap[ParamTrailingSlash]
+	trailingSlashName, _ := ts.(string) //nolint:errcheck
+
+	trailingSlash, err := urlnorm.ParseTrailingSlash(trailingSlashName)
+	if err != nil {
+		return fmt.Errorf("bad arg [%v]: %w", ParamTrailingSlash, err)
+	}
 
 	cr := core.New(core.Config{
-		URL:      url,
-		NWorkers: NWorkers,
-		MaxDepth: MaxDepth,
+		URL:          startURL,
+		NWorkers:     NWorkers,
+		MaxDepth:     MaxDepth,
+		Scope:        scope,
+		AllowedHosts: splitList(allowedHosts),
+		Include:      splitPatterns(include),
+		Exclude:      splitPatterns(exclude),
+		Normalize:    normalize,
+		Normalization: urlnorm.Config{
+			StripParams:   stripParams,
+			TrailingSlash: trailingSlash,
+		},
+		RespectRobots:      !ignoreRobots,
+		RobotsAgent:        robotsAgent,
+		HonorNofollowLinks: honorNofollow,
+		SeedSitemaps:       seedSitemaps,
+		Sitemaps:           splitList(sitemapsList),
+		SeedLevel:          seedLevel,
+		Limiter:            limiter,
 	}, pageLoader, reportSaver)
 
 	if err := cr.Run(ctx); err != nil {
 		return err
 	}
 
+	if skipped := cr.Skipped(); len(skipped) > 0 {
+		log.Printf("%d pages were skipped", len(skipped))
+	}
+
+	if redirects := cr.Redirects(); len(redirects) > 0 {
+		log.Printf("%d pages redirect to other URLs, final URLs are saved", len(redirects))
+	}
+
+	nonReciprocal := 0
+	for _, cluster := range cr.Clusters() {
+		if !cluster.Reciprocal() {
+			nonReciprocal++
+		}
+	}
+
+	if nonReciprocal > 0 {
+		log.Printf("%d hreflang clusters are not reciprocal", nonReciprocal)
+	}
+
+	if failed := cr.Failed(); len(failed) > 0 {
+		log.Printf("%d pages failed to load:", len(failed))
+
+		for pageURL, reason := range failed {
+			log.Printf("  [%v]: %v", pageURL, reason)
+		}
+	}
+
+	for canonical, variants := range cr.Variants() {
+		log.Printf("canonical page [%v] has %d non-canonical variants: %v", canonical, len(variants), variants)
+	}
+
 	return nil
 }
 
+// parseArgs parses -key=value args according to mapKeys value types.
+// A value may contain "=". []string args may be repeated, for other types the last value is used.
 func parseArgs(args []string, mapKeys map[string]interface{}) (map[string]interface{}, error) {
 
-	paramsMap := make(map[string]interface{}, 3)
+	paramsMap := make(map[string][]string, 3)
 
 	for _, arg := range args {
-		params := strings.Split(strings.TrimPrefix(arg, "-"), "=")
-		if len(params) != 2 {
+		key, value, ok := strings.Cut(strings.TrimPrefix(arg, "-"), "=")
+		if !ok {
 			return nil, fmt.Errorf("bad argument: %v", arg)
 		}
 
-		paramsMap[params[0]] = params[1]
+		paramsMap[key] = append(paramsMap[key], value)
 	}
 
 	res := make(map[string]interface{}, 3)
 
 	for k, v := range mapKeys {
 
-		arg, ok := paramsMap[k]
+		values, ok := paramsMap[k]
 		if !ok {
 			continue
 		}
 
-		stringArg, _ := arg.(string) //nolint:errcheck
+		stringArg := values[len(values)-1]
 
 		switch v.(type) {
 		case string:
 			res[k] = stringArg
 
+		case []string:
+			res[k] = values
+
 		case int:
 
 			n, err := strconv.ParseInt(stringArg, 10, 32)
@@ -150,12 +693,215 @@ func parseArgs(args []string, mapKeys map[string]interface{}) (map[string]interf
 			}
 
 			res[k] = int(n)
+
+		case float64:
+
+			f, err := strconv.ParseFloat(stringArg, 64)
+			if err != nil {
+				return nil, fmt.Errorf("arg [%v] should be numeric [%v]", k, stringArg)
+			}
+
+			res[k] = f
+
+		case bool:
+
+			b, err := strconv.ParseBool(stringArg)
+			if err != nil {
+				return nil, fmt.Errorf("arg [%v] should be boolean [%v]", k, stringArg)
+			}
+
+			res[k] = b
 		}
 	}
 
 	return res, nil
 }
 
+// durationArg returns a duration arg value or a default value if the arg isn't set.
+func durationArg(argsMap map[string]interface{}, key string, defaultValue time.Duration) (time.Duration, error) {
+
+	s, _ := argsMap[key].(string) //nolint:errcheck
+	if len(s) == 0 {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("arg [%v] should be a duration [%v]", key, s)
+	}
+
+	return d, nil
+}
+
+// baseURLArg returns -base-url arg value or a scheme and a host of the start URL if the arg isn't set.
+func baseURLArg(argsMap map[string]interface{}, startURL string) (string, error) {
+
+	if baseURL, _ := argsMap[ParamBaseURL].(string); len(baseURL) > 0 { //nolint:errcheck
+		return baseURL, nil
+	}
+
+	return rootURL(startURL)
+}
+
+// parseHeaders parses "Name: value" headers.
+func parseHeaders(list []string) (http.Header, error) {
+
+	res := make(http.Header, len(list))
+
+	for _, h := range list {
+		name, value, ok := strings.Cut(h, ":")
+		if name = strings.TrimSpace(name); !ok || len(name) == 0 {
+			return nil, fmt.Errorf("arg [%v] should be \"Name: value\" [%v]", ParamHeader, h)
+		}
+
+		res.Add(name, strings.TrimSpace(value))
+	}
+
+	return res, nil
+}
+
+// urlHost returns a host of a URL.
+func urlHost(pageURL string) (string, error) {
+
+	u, err := neturl.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("bad URL [%v]: %w", pageURL, err)
+	}
+
+	return u.Hostname(), nil
+}
+
+// startHost returns a host of the final start URL. The start URL may redirect to a host in the crawl scope
+// of the original URL or to its www equivalent, otherwise credentials and the crawl would go to a host
+// (like an SSO page) that wasn't asked for.
+func startHost(scope core.Scope, finalURL string) (string, error) {
+
+	u, err := neturl.Parse(finalURL)
+	if err != nil {
+		return "", fmt.Errorf("bad URL [%v]: %w", finalURL, err)
+	}
+
+	www := core.Scope{Mode: core.ScopeWWW, Host: scope.Host}
+
+	if !scope.Contains(u) && !www.Contains(u) {
+		return "", fmt.Errorf("start URL redirects to [%v] that is out of the crawl scope of [%v]", finalURL, scope.Host)
+	}
+
+	return u.Hostname(), nil
+}
+
+// newAuthScope returns a function that checks if credentials may be sent to a URL.
+// They are sent to the crawl scope and to the login page host. The scope is read on every check,
+// so it follows the start URL host if it's changed later.
+func newAuthScope(scope *core.Scope, argsMap map[string]interface{}) (func(*neturl.URL) bool, error) {
+
+	var loginHost string
+
+	if loginURL, _ := argsMap[ParamLoginURL].(string); len(loginURL) > 0 { //nolint:errcheck
+		lu, err := neturl.Parse(loginURL)
+		if err != nil {
+			return nil, fmt.Errorf("bad arg [%v]: %w", ParamLoginURL, err)
+		}
+
+		loginHost = strings.ToLower(lu.Hostname())
+	}
+
+	return func(u *neturl.URL) bool {
+		return scope.Contains(u) || (len(loginHost) > 0 && strings.ToLower(u.Hostname()) == loginHost)
+	}, nil
+}
+
+// parseFields parses "name=value" form fields.
+func parseFields(list []string) (neturl.Values, error) {
+
+	res := make(neturl.Values, len(list))
+
+	for _, f := range list {
+		name, value, ok := strings.Cut(f, "=")
+		if !ok || len(name) == 0 {
+			return nil, fmt.Errorf("arg [%v] should be \"name=value\" [%v]", ParamLoginField, f)
+		}
+
+		res.Add(name, value)
+	}
+
+	return res, nil
+}
+
+// parseCookies parses "name=value" cookies.
+func parseCookies(list []string) ([]*http.Cookie, error) {
+
+	res := make([]*http.Cookie, 0, len(list))
+
+	for _, c := range list {
+		name, value, ok := strings.Cut(c, "=")
+		if name = strings.TrimSpace(name); !ok || len(name) == 0 {
+			return nil, fmt.Errorf("arg [%v] should be \"name=value\" [%v]", ParamCookie, c)
+		}
+
+		res = append(res, &http.Cookie{Name: name, Value: strings.TrimSpace(value)})
+	}
+
+	return res, nil
+}
+
+// splitList splits a comma-separated list and drops empty items.
+func splitList(s string) []string {
+
+	var res []string
+
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
+
+// splitPatterns splits comma-separated pattern lists of repeated args and drops empty patterns.
+// "\," is a comma inside a pattern, e.g. re:/\d{2\,4}/, other backslashes are kept as is.
+func splitPatterns(lists []string) []string {
+
+	var res []string
+
+	for _, list := range lists {
+
+		var sb strings.Builder
+
+		for i := 0; i <= len(list); i++ {
+
+			switch {
+			case i == len(list) || list[i] == ',':
+				if item := strings.TrimSpace(sb.String()); len(item) > 0 {
+					res = append(res, item)
+				}
+
+				sb.Reset()
+
+			case list[i] == '\\' && i+1 < len(list) && list[i+1] == ',':
+				sb.WriteByte(',')
+				i++
+
+			default:
+				sb.WriteByte(list[i])
+			}
+		}
+	}
+
+	return res
+}
+
+// rootURL returns a scheme and a host of the URL.
+func rootURL(pageURL string) (string, error) {
+	u, err := neturl.Parse(pageURL)
+	if err != nil {
+		return "", fmt.Errorf("bad URL [%v]: %w", pageURL, err)
+	}
+
+	return u.Scheme + "://" + u.Host, nil
+}
+
 func setupGracefulShutdown(stop func()) {
 	signalChannel := make(chan os.Signal, 1)
 	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
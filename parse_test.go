@@ -1,9 +1,12 @@
 package main
 
 import (
+	"net/http"
+	neturl "net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"github.com/yurii-vyrovyi/sitemap-generator/internal/core"
 )
 
 func Test_parseArgs(t *testing.T) {
@@ -35,6 +38,57 @@ func Test_parseArgs(t *testing.T) {
 			},
 		},
 
+		"bool": {
+			src:    []string{"-gzip=true"},
+			expErr: false,
+			expRes: map[string]interface{}{
+				ParamGzip: true,
+			},
+		},
+
+		"float": {
+			src:    []string{"-rps=0.5"},
+			expErr: false,
+			expRes: map[string]interface{}{
+				ParamRPS: 0.5,
+			},
+		},
+
+		"-rps is not float": {
+			src:    []string{"-rps=fast"},
+			expErr: true,
+			expRes: nil,
+		},
+
+		"value with =": {
+			src:    []string{"-output-file=./a=b.xml"},
+			expErr: false,
+			expRes: map[string]interface{}{
+				ParamOutputFile: "./a=b.xml",
+			},
+		},
+
+		"repeated": {
+			src:    []string{"-header=X-A: 1", "-header=X-B: 2", "-max-depth=4", "-max-depth=5"},
+			expErr: false,
+			expRes: map[string]interface{}{
+				ParamHeader:   []string{"X-A: 1", "X-B: 2"},
+				ParamMaxDepth: 5,
+			},
+		},
+
+		"no value": {
+			src:    []string{"-gzip"},
+			expErr: true,
+			expRes: nil,
+		},
+
+		"-gzip is not bool": {
+			src:    []string{"-gzip=abc"},
+			expErr: true,
+			expRes: nil,
+		},
+
 		"-parallel is not int": {
 			src:    []string{"-parallel=abc", "-output-file=./sitemap.out", "-max-depth=4"},
 			expErr: true,
@@ -46,6 +100,9 @@ func Test_parseArgs(t *testing.T) {
 		ParamParallel:   0,
 		ParamOutputFile: "",
 		ParamMaxDepth:   0,
+		ParamGzip:       false,
+		ParamRPS:        0.0,
+		ParamHeader:     []string(nil),
 	}
 
 	//nolint:paralleltest
@@ -69,3 +126,156 @@ func Test_parseArgs(t *testing.T) {
 	}
 
 }
+
+func Test_splitPatterns(t *testing.T) {
+	t.Parallel()
+
+	res := splitPatterns([]string{`/blog/**, re:/\d{2\,4}/,`, `re:[?&]page=`})
+	require.Equal(t, []string{"/blog/**", `re:/\d{2,4}/`, "re:[?&]page="}, res)
+
+	require.Empty(t, splitPatterns(nil))
+}
+
+func Test_baseURLArg(t *testing.T) {
+	t.Parallel()
+
+	// the start URL is resolved, e.g. http://e.com redirects to https://www.e.com/
+	baseURL, err := baseURLArg(map[string]interface{}{}, "https://www.e.com/")
+	require.NoError(t, err)
+	require.Equal(t, "https://www.e.com", baseURL)
+
+	baseURL, err = baseURLArg(map[string]interface{}{ParamBaseURL: "https://cdn.e.com/sitemaps"}, "https://www.e.com/")
+	require.NoError(t, err)
+	require.Equal(t, "https://cdn.e.com/sitemaps", baseURL)
+}
+
+func Test_startHost(t *testing.T) {
+	t.Parallel()
+
+	type Test struct {
+		scope   core.Scope
+		url     string
+		expHost string
+		expErr  bool
+	}
+
+	tests := map[string]Test{
+		"www equivalent": {
+			scope:   core.Scope{Mode: core.ScopeHost, Host: "e.com"},
+			url:     "https://www.e.com/",
+			expHost: "www.e.com",
+		},
+
+		"subdomain in scope": {
+			scope:   core.Scope{Mode: core.ScopeSubdomains, Host: "e.com"},
+			url:     "https://blog.e.com/",
+			expHost: "blog.e.com",
+		},
+
+		"subdomain out of scope": {
+			scope:  core.Scope{Mode: core.ScopeHost, Host: "staging.e.com"},
+			url:    "https://sso.e.com/login",
+			expErr: true,
+		},
+
+		"other host": {
+			scope:  core.Scope{Mode: core.ScopeWWW, Host: "e.com"},
+			url:    "https://login.other.com/",
+			expErr: true,
+		},
+	}
+
+	//nolint:paralleltest
+	for description, test := range tests {
+		test := test
+
+		t.Run(description, func(t *testing.T) {
+			t.Parallel()
+
+			host, err := startHost(test.scope, test.url)
+
+			if test.expErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.expHost, host)
+		})
+	}
+}
+
+func Test_parseHeaders(t *testing.T) {
+	t.Parallel()
+
+	res, err := parseHeaders([]string{"Accept-Language: en", "x-test:a:b", "X-Test: c"})
+	require.NoError(t, err)
+	require.Equal(t, http.Header{
+		"Accept-Language": {"en"},
+		"X-Test":          {"a:b", "c"},
+	}, res)
+
+	_, err = parseHeaders([]string{"no colon"})
+	require.Error(t, err)
+
+	_, err = parseHeaders([]string{": value"})
+	require.Error(t, err)
+}
+
+func Test_parseCookies(t *testing.T) {
+	t.Parallel()
+
+	res, err := parseCookies([]string{"session=a=b", "lang = en"})
+	require.NoError(t, err)
+	require.Equal(t, []*http.Cookie{
+		{Name: "session", Value: "a=b"},
+		{Name: "lang", Value: "en"},
+	}, res)
+
+	_, err = parseCookies([]string{"session"})
+	require.Error(t, err)
+}
+
+func Test_parseFields(t *testing.T) {
+	t.Parallel()
+
+	res, err := parseFields([]string{"user=admin", "password=a=b", "empty="})
+	require.NoError(t, err)
+	require.Equal(t, neturl.Values{
+		"user":     {"admin"},
+		"password": {"a=b"},
+		"empty":    {""},
+	}, res)
+
+	_, err = parseFields([]string{"user"})
+	require.Error(t, err)
+}
+
+func Test_newAuthScope(t *testing.T) {
+	t.Parallel()
+
+	scope := core.Scope{Mode: core.ScopeWWW, Host: "staging.e.com"}
+
+	inScope, err := newAuthScope(&scope, map[string]interface{}{
+		ParamLoginURL: "https://sso.e.com/login",
+	})
+	require.NoError(t, err)
+
+	for u, exp := range map[string]bool{
+		"https://staging.e.com/page":     true,
+		"https://www.staging.e.com/page": true,
+		"https://sso.e.com/session":      true,
+		"https://e.com/":                 false,
+		"https://cdn.other.com/img.png":  false,
+	} {
+		pageURL, err := neturl.Parse(u)
+		require.NoError(t, err)
+		require.Equal(t, exp, inScope(pageURL), u)
+	}
+
+	scope.Host = "e.com"
+
+	pageURL, err := neturl.Parse("https://e.com/")
+	require.NoError(t, err)
+	require.True(t, inScope(pageURL))
+}